@@ -9,4 +9,7 @@ require (
 	github.com/gorilla/mux v1.8.1
 	github.com/joho/godotenv v1.5.1
 	golang.org/x/crypto v0.15.0
+	golang.org/x/text v0.14.0
 )
+
+require golang.org/x/sys v0.14.0 // indirect
@@ -1,8 +1,13 @@
 package main
 
 import (
+	"api/src/auth"
 	"api/src/config"
 	"api/src/router"
+	"api/src/scheduler"
+	"api/src/security"
+	"api/src/storage"
+	"api/src/throttle"
 	"fmt"
 	"log"
 	"net/http"
@@ -10,6 +15,17 @@ import (
 
 func main() {
 	config.Load()
+	if err := auth.StartKeyRotation(); err != nil {
+		log.Fatal(err)
+	}
+	throttle.Configure()
+	if err := security.Configure(); err != nil {
+		log.Fatal(err)
+	}
+	if err := storage.Configure(); err != nil {
+		log.Fatal(err)
+	}
+	scheduler.Start()
 	fmt.Println("Rodando")
 
 	r := router.Router()
@@ -1,6 +1,30 @@
 CREATE DATABASE IF NOT EXISTS devbook;
 USE devbook;
 
+DROP TABLE IF EXISTS attachments;
+DROP TABLE IF EXISTS post_revisions;
+DROP TABLE IF EXISTS bookmarks;
+DROP TABLE IF EXISTS notifications;
+DROP TABLE IF EXISTS user_blocks;
+DROP TABLE IF EXISTS post_mentions;
+DROP TABLE IF EXISTS post_hashtags;
+DROP TABLE IF EXISTS comments;
+DROP TABLE IF EXISTS post_likes;
+DROP TABLE IF EXISTS magic_links;
+DROP TABLE IF EXISTS sessions;
+DROP TABLE IF EXISTS oauth_authorization_codes;
+DROP TABLE IF EXISTS personal_access_tokens;
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS recovery_codes;
+DROP TABLE IF EXISTS email_verifications;
+DROP TABLE IF EXISTS password_resets;
+DROP TABLE IF EXISTS audit_logs;
+DROP TABLE IF EXISTS user_roles;
+DROP TABLE IF EXISTS roles;
+DROP TABLE IF EXISTS signing_keys;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
+DROP TABLE IF EXISTS oauth_clients;
 DROP TABLE IF EXISTS users;
 DROP TABLE IF EXISTS followers;
 DROP TABLE IF EXISTS posts;
@@ -11,7 +35,19 @@ CREATE TABLE users(
     nick varchar(50) NOT NULL,
     email varchar(50) NOT NULL,
     password varchar(150) NOT NULL,
-    created_at timestamp default current_timestamp()
+    email_verified_at timestamp NULL DEFAULT NULL,
+    tokens_revoked_at timestamp NULL DEFAULT NULL,
+    totp_secret varchar(64) NULL DEFAULT NULL,
+    totp_enabled boolean NOT NULL DEFAULT false,
+    totp_last_step bigint NOT NULL DEFAULT 0,
+    avatar_key varchar(255) NULL DEFAULT NULL,
+    banner_key varchar(255) NULL DEFAULT NULL,
+    created_at timestamp default current_timestamp(),
+    deleted_at timestamp NULL DEFAULT NULL,
+    deleted_by int NULL DEFAULT NULL,
+    FOREIGN KEY (deleted_by)
+    REFERENCES users(id)
+    ON DELETE SET NULL
 ) ENGINE=INNODB;
 
 CREATE TABLE followers(
@@ -39,5 +75,418 @@ CREATE TABLE posts(
     ON DELETE CASCADE,
 
     likes int  default 0,
+    created_at timestamp default current_timestamp,
+
+    kind enum('post', 'repost', 'quote') not null default 'post',
+    repost_of int null default null,
+    FOREIGN KEY (repost_of)
+    REFERENCES posts(id)
+    ON DELETE SET NULL,
+
+    status enum('draft', 'scheduled', 'published') not null default 'published',
+    publish_at timestamp null default null,
+    INDEX (status, publish_at),
+
+    edited_at timestamp null default null,
+
+    deleted_at timestamp null default null,
+    deleted_by int null default null,
+    FOREIGN KEY (deleted_by)
+    REFERENCES users(id)
+    ON DELETE SET NULL,
+
+    repost_key int as (if(kind = 'repost' and deleted_at is null, repost_of, null)) virtual,
+    UNIQUE (author_id, repost_key)
+)ENGINE=INNODB;
+
+CREATE TABLE oauth_clients(
+    id varchar(64) primary key,
+    secret_hash char(64) null default null,
+    name varchar(100) not null,
+    redirect_uris varchar(1000) not null,
+
+    owner_id int not null,
+    FOREIGN KEY (owner_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp
+)ENGINE=INNODB;
+
+CREATE TABLE refresh_tokens(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    client_id varchar(64) null default null,
+    FOREIGN KEY (client_id)
+    REFERENCES oauth_clients(id)
+    ON DELETE CASCADE,
+
+    family_id varchar(64) not null,
+    scopes varchar(255) not null default '',
+    token_hash char(64) not null unique,
+    expires_at datetime not null,
+    used_at timestamp null default null,
+    revoked_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+
+    INDEX (family_id)
+)ENGINE=INNODB;
+
+CREATE TABLE revoked_tokens(
+    jti varchar(64) primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    expires_at datetime not null,
+    created_at timestamp default current_timestamp
+)ENGINE=INNODB;
+
+CREATE TABLE signing_keys(
+    kid varchar(64) primary key,
+    algorithm varchar(10) not null,
+    private_key text not null,
+    public_key text not null,
+    created_at timestamp default current_timestamp,
+    signs_at datetime not null,
+    retires_at datetime null default null
+)ENGINE=INNODB;
+
+CREATE TABLE roles(
+    id int auto_increment primary key,
+    name varchar(20) not null unique
+)ENGINE=INNODB;
+
+INSERT INTO roles (name) VALUES ('admin'), ('moderator');
+
+CREATE TABLE user_roles(
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    role_id int not null,
+    FOREIGN KEY (role_id)
+    REFERENCES roles(id)
+    ON DELETE CASCADE,
+
+    primary key(user_id, role_id)
+)ENGINE=INNODB;
+
+CREATE TABLE audit_logs(
+    id int auto_increment primary key,
+
+    actor_id int null,
+    FOREIGN KEY (actor_id)
+    REFERENCES users(id)
+    ON DELETE SET NULL,
+
+    action varchar(50) not null,
+    target_type varchar(20) not null,
+    target_id int not null,
+    created_at timestamp default current_timestamp
+)ENGINE=INNODB;
+
+CREATE TABLE password_resets(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    token_hash char(64) not null unique,
+    expires_at datetime not null,
+    used_at timestamp null default null,
     created_at timestamp default current_timestamp
-)ENGINE=INNODB;
\ No newline at end of file
+)ENGINE=INNODB;
+
+CREATE TABLE email_verifications(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    email varchar(50) not null,
+    token_hash char(64) not null unique,
+    expires_at datetime not null,
+    used_at timestamp null default null,
+    created_at timestamp default current_timestamp
+)ENGINE=INNODB;
+
+CREATE TABLE recovery_codes(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    code_hash char(64) not null,
+    used_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+
+    INDEX (user_id, code_hash)
+)ENGINE=INNODB;
+
+CREATE TABLE login_attempts(
+    attempt_key varchar(191) primary key,
+    failures int not null default 0,
+    last_failure_at datetime not null,
+    blocked_until datetime null default null
+)ENGINE=INNODB;
+
+CREATE TABLE personal_access_tokens(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    name varchar(50) not null,
+    token_prefix varchar(16) not null,
+    token_hash char(64) not null unique,
+    scopes varchar(255) not null,
+    expires_at datetime null default null,
+    last_used_at timestamp null default null,
+    revoked_at timestamp null default null,
+    created_at timestamp default current_timestamp
+)ENGINE=INNODB;
+
+CREATE TABLE oauth_authorization_codes(
+    id int auto_increment primary key,
+    code_hash char(64) not null unique,
+
+    client_id varchar(64) not null,
+    FOREIGN KEY (client_id)
+    REFERENCES oauth_clients(id)
+    ON DELETE CASCADE,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    redirect_uri varchar(255) not null,
+    scopes varchar(255) not null,
+    code_challenge varchar(128) not null,
+    code_challenge_method varchar(10) not null,
+    family_id varchar(64) null default null,
+    expires_at datetime not null,
+    used_at timestamp null default null,
+    created_at timestamp default current_timestamp
+)ENGINE=INNODB;
+
+CREATE TABLE sessions(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    family_id varchar(64) not null unique,
+    user_agent varchar(255) not null,
+    ip varchar(45) not null,
+    created_at timestamp default current_timestamp,
+    last_seen_at timestamp default current_timestamp,
+    revoked_at timestamp null default null
+)ENGINE=INNODB;
+
+CREATE TABLE magic_links(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    email varchar(50) not null,
+    token_hash char(64) not null unique,
+    expires_at datetime not null,
+    used_at timestamp null default null,
+    created_at timestamp default current_timestamp
+)ENGINE=INNODB;
+
+CREATE TABLE post_likes(
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    post_id int not null,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp,
+
+    primary key(user_id, post_id),
+    INDEX (post_id, created_at)
+)ENGINE=INNODB;
+
+CREATE TABLE comments(
+    id int auto_increment primary key,
+
+    post_id int not null,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    parent_id int null default null,
+    FOREIGN KEY (parent_id)
+    REFERENCES comments(id)
+    ON DELETE CASCADE,
+
+    author_id int not null,
+    FOREIGN KEY (author_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    content varchar(500) not null,
+    created_at timestamp default current_timestamp,
+    updated_at timestamp null default null,
+
+    INDEX (post_id, parent_id, id)
+)ENGINE=INNODB;
+
+CREATE TABLE post_hashtags(
+    post_id int not null,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    tag varchar(100) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin not null,
+
+    primary key(post_id, tag),
+    INDEX (tag)
+)ENGINE=INNODB;
+
+CREATE TABLE post_mentions(
+    post_id int not null,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    field enum('title', 'content') not null,
+    start int not null,
+    length int not null,
+
+    primary key(post_id, field, start),
+    INDEX (user_id, post_id)
+)ENGINE=INNODB;
+
+CREATE TABLE user_blocks(
+    blocker_id int not null,
+    FOREIGN KEY (blocker_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    blocked_id int not null,
+    FOREIGN KEY (blocked_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp,
+
+    primary key(blocker_id, blocked_id)
+)ENGINE=INNODB;
+
+CREATE TABLE notifications(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    actor_id int not null,
+    FOREIGN KEY (actor_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    type varchar(20) not null,
+
+    post_id int null default null,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    read_at timestamp null default null,
+    created_at timestamp default current_timestamp,
+
+    INDEX (user_id, id)
+)ENGINE=INNODB;
+
+CREATE TABLE bookmarks(
+    id int auto_increment primary key,
+
+    user_id int not null,
+    FOREIGN KEY (user_id)
+    REFERENCES users(id)
+    ON DELETE CASCADE,
+
+    post_id int not null,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    created_at timestamp default current_timestamp,
+
+    UNIQUE (user_id, post_id)
+) ENGINE=INNODB;
+
+CREATE TABLE post_revisions(
+    id int auto_increment primary key,
+
+    post_id int not null,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    revision int not null,
+    title varchar(50) not null,
+    content varchar(500) not null,
+
+    editor_id int null default null,
+    FOREIGN KEY (editor_id)
+    REFERENCES users(id)
+    ON DELETE SET NULL,
+
+    replaced_at timestamp default current_timestamp,
+
+    UNIQUE (post_id, revision)
+) ENGINE=INNODB;
+
+CREATE TABLE attachments(
+    id int auto_increment primary key,
+
+    post_id int not null,
+    FOREIGN KEY (post_id)
+    REFERENCES posts(id)
+    ON DELETE CASCADE,
+
+    storage_key varchar(255) not null unique,
+    thumbnail_key varchar(255) not null,
+    content_type varchar(100) not null,
+    size int not null,
+    width int not null default 0,
+    height int not null default 0,
+
+    created_at timestamp default current_timestamp
+) ENGINE=INNODB;
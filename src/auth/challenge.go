@@ -0,0 +1,37 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+const twoFactorPurpose = "2fa"
+
+//CreateChallengeToken gera o token de curta duração devolvido pelo login quando o usuário tem 2FA.
+//Ele não serve como access token, apenas para ser trocado em /login/2fa junto com o código.
+func CreateChallengeToken(userID uint64) (string, error) {
+	permissions := jwt.MapClaims{}
+	permissions["purpose"] = twoFactorPurpose
+	permissions["exp"] = time.Now().Add(time.Minute * 5).Unix()
+	permissions["userID"] = userID
+
+	return signToken(permissions)
+}
+
+//ParseChallengeToken retorna o usuário de um token de desafio do 2FA válido
+func ParseChallengeToken(tokenString string) (uint64, error) {
+	permissions, err := parseTokenString(tokenString)
+	if err != nil {
+		return 0, err
+	}
+
+	if permissions["purpose"] != twoFactorPurpose {
+		return 0, errors.New("invalid token")
+	}
+
+	return strconv.ParseUint(fmt.Sprintf("%.0f", permissions["userID"]), 10, 64)
+}
@@ -0,0 +1,338 @@
+package auth
+
+import (
+	"api/src/config"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"sync"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+const keyRotationLock = "devbook_signing_keys"
+
+//JWKSMaxAge é por quanto tempo o JWKS pode ficar em cache. Uma chave nova fica publicada por keyPublishDelay,
+//que cobre esse cache e o recarregamento das outras réplicas em findKey, antes de começar a assinar
+const (
+	JWKSMaxAge      = 5 * time.Minute
+	keyPublishDelay = JWKSMaxAge + time.Minute
+)
+
+type signingKey struct {
+	id      string
+	method  jwt.SigningMethod
+	private interface{}
+	public  interface{}
+	signsAt time.Time
+}
+
+//keySet guarda em memória as chaves carregadas do banco. signing são as que podem assinar, da mais nova
+//para a mais antiga; a usada é a primeira cujo signsAt já passou.
+var keySet = struct {
+	sync.RWMutex
+	keys     map[string]signingKey
+	signing  []signingKey
+	loadedAt time.Time
+}{keys: map[string]signingKey{}}
+
+//StartKeyRotation carrega as chaves de assinatura e, a cada minuto, gera uma chave nova quando a atual
+//passa do intervalo de rotação. As chaves antigas continuam verificando tokens durante a janela de sobreposição.
+//Com HS256 não há chaves para gerenciar e nada é feito.
+func StartKeyRotation() error {
+	if !isAsymmetric(config.JWTAlgorithm) {
+		return nil
+	}
+
+	if err := rotateKeys(); err != nil {
+		return err
+	}
+
+	go func() {
+		for range time.Tick(time.Minute) {
+			if err := rotateKeys(); err != nil {
+				log.Printf("erro ao rotacionar as chaves de assinatura: %v", err)
+			}
+		}
+	}()
+
+	return nil
+}
+
+//PublicKeys retorna o JWKS com as chaves públicas que ainda verificam tokens
+func PublicKeys() models.JWKS {
+	keySet.RLock()
+	defer keySet.RUnlock()
+
+	jwks := models.JWKS{Keys: []models.JWK{}}
+	for _, key := range keySet.keys {
+		jwk, ok := toJWK(key)
+		if ok {
+			jwks.Keys = append(jwks.Keys, jwk)
+		}
+	}
+
+	return jwks
+}
+
+func isAsymmetric(algorithm string) bool {
+	return algorithm == jwt.SigningMethodRS256.Alg() ||
+		algorithm == jwt.SigningMethodES256.Alg() ||
+		algorithm == SigningMethodEdDSA.Alg()
+}
+
+func rotateKeys() error {
+	db, err := db.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	locks := repositories.NewLockRep(db)
+	acquired, err := locks.Acquire(keyRotationLock)
+	if err != nil {
+		return err
+	}
+
+	//se outra réplica está rotacionando, basta recarregar as chaves que ela gerar
+	if acquired {
+		err = rotateIfNeeded(repositories.NewSigningKeyRep(db))
+		if releaseErr := locks.Release(keyRotationLock); err == nil {
+			err = releaseErr
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	return loadKeys(repositories.NewSigningKeyRep(db))
+}
+
+func rotateIfNeeded(rep *repositories.SigningKeys) error {
+	latest, err := rep.Latest()
+	if err != nil {
+		return err
+	}
+
+	if latest.KID != "" && latest.Algorithm == config.JWTAlgorithm && time.Since(latest.CreatedAt) < config.KeyRotationInterval {
+		return nil
+	}
+
+	key, err := generateKey(config.JWTAlgorithm)
+	if err != nil {
+		return err
+	}
+
+	//a chave atual continua assinando enquanto a nova é publicada; sem uma chave do mesmo algoritmo
+	//não há quem assine no lugar dela, então a nova começa na hora
+	key.SignsAt = time.Now()
+	if latest.KID != "" && latest.Algorithm == config.JWTAlgorithm {
+		key.SignsAt = key.SignsAt.Add(keyPublishDelay)
+	}
+
+	if err = rep.Create(key); err != nil {
+		return err
+	}
+
+	if err = rep.RetireOthers(key.KID, key.SignsAt.Add(config.KeyRotationOverlap)); err != nil {
+		return err
+	}
+
+	return rep.DeleteRetired()
+}
+
+func loadKeys(rep *repositories.SigningKeys) error {
+	stored, err := rep.SearchActive()
+	if err != nil {
+		return err
+	}
+
+	keys := map[string]signingKey{}
+	var signing []signingKey
+
+	//SearchActive ordena da mais nova para a mais antiga
+	for _, storedKey := range stored {
+		key, err := parseKey(storedKey)
+		if err != nil {
+			return err
+		}
+
+		keys[key.id] = key
+		if key.method.Alg() == config.JWTAlgorithm {
+			signing = append(signing, key)
+		}
+	}
+
+	keySet.Lock()
+	defer keySet.Unlock()
+
+	keySet.keys = keys
+	keySet.signing = signing
+	keySet.loadedAt = time.Now()
+
+	return nil
+}
+
+//findKey procura a chave pelo kid; uma chave desconhecida pode ter sido gerada por outra réplica,
+//então as chaves são recarregadas, no máximo uma vez a cada 10 segundos
+func findKey(kid string) (signingKey, bool) {
+	keySet.RLock()
+	key, ok := keySet.keys[kid]
+	loadedAt := keySet.loadedAt
+	keySet.RUnlock()
+
+	if ok || time.Since(loadedAt) < 10*time.Second {
+		return key, ok
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		return signingKey{}, false
+	}
+	defer db.Close()
+
+	if err = loadKeys(repositories.NewSigningKeyRep(db)); err != nil {
+		log.Printf("erro ao recarregar as chaves de assinatura: %v", err)
+		return signingKey{}, false
+	}
+
+	keySet.RLock()
+	defer keySet.RUnlock()
+
+	key, ok = keySet.keys[kid]
+	return key, ok
+}
+
+func currentKey() (signingKey, error) {
+	keySet.RLock()
+	defer keySet.RUnlock()
+
+	now := time.Now()
+	for _, key := range keySet.signing {
+		if !key.signsAt.After(now) {
+			return key, nil
+		}
+	}
+
+	return signingKey{}, errors.New("nenhuma chave de assinatura disponível")
+}
+
+func generateKey(algorithm string) (models.SigningKey, error) {
+	var privateKey interface{}
+	var publicKey interface{}
+
+	switch algorithm {
+	case jwt.SigningMethodRS256.Alg():
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			return models.SigningKey{}, err
+		}
+		privateKey, publicKey = key, &key.PublicKey
+	case jwt.SigningMethodES256.Alg():
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return models.SigningKey{}, err
+		}
+		privateKey, publicKey = key, &key.PublicKey
+	case SigningMethodEdDSA.Alg():
+		public, private, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return models.SigningKey{}, err
+		}
+		privateKey, publicKey = private, public
+	default:
+		return models.SigningKey{}, fmt.Errorf("algoritmo de assinatura não suportado %s", algorithm)
+	}
+
+	privateDER, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	publicDER, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	kid, err := NewOpaqueToken(12)
+	if err != nil {
+		return models.SigningKey{}, err
+	}
+
+	return models.SigningKey{
+		KID:        kid,
+		Algorithm:  algorithm,
+		PrivateKey: string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER})),
+		PublicKey:  string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER})),
+	}, nil
+}
+
+func parseKey(stored models.SigningKey) (signingKey, error) {
+	method := jwt.GetSigningMethod(stored.Algorithm)
+	if method == nil {
+		return signingKey{}, fmt.Errorf("algoritmo de assinatura não suportado %s", stored.Algorithm)
+	}
+
+	privateBlock, _ := pem.Decode([]byte(stored.PrivateKey))
+	publicBlock, _ := pem.Decode([]byte(stored.PublicKey))
+	if privateBlock == nil || publicBlock == nil {
+		return signingKey{}, fmt.Errorf("chave %s corrompida", stored.KID)
+	}
+
+	privateKey, err := x509.ParsePKCS8PrivateKey(privateBlock.Bytes)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(publicBlock.Bytes)
+	if err != nil {
+		return signingKey{}, err
+	}
+
+	return signingKey{
+		id:      stored.KID,
+		method:  method,
+		private: privateKey,
+		public:  publicKey,
+		signsAt: stored.SignsAt,
+	}, nil
+}
+
+func toJWK(key signingKey) (models.JWK, bool) {
+	jwk := models.JWK{KeyID: key.id, Algorithm: key.method.Alg(), Use: "sig"}
+	encode := base64.RawURLEncoding.EncodeToString
+
+	switch public := key.public.(type) {
+	case *rsa.PublicKey:
+		jwk.KeyType = "RSA"
+		jwk.N = encode(public.N.Bytes())
+		jwk.E = encode(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		jwk.KeyType = "EC"
+		jwk.Curve = public.Curve.Params().Name
+		jwk.X = encode(public.X.FillBytes(make([]byte, size)))
+		jwk.Y = encode(public.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.KeyType = "OKP"
+		jwk.Curve = "Ed25519"
+		jwk.X = encode(public)
+	default:
+		return models.JWK{}, false
+	}
+
+	return jwk, true
+}
@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"api/src/config"
+	"testing"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+//newTestKey gera uma chave do algoritmo informado que assina a partir de signsAt
+func newTestKey(t *testing.T, algorithm string, signsAt time.Time) signingKey {
+	t.Helper()
+
+	stored, err := generateKey(algorithm)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored.SignsAt = signsAt
+
+	key, err := parseKey(stored)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+//useKeys troca as chaves em memória pelas informadas, da mais nova para a mais antiga, sem passar pelo banco
+func useKeys(t *testing.T, algorithm string, keys ...signingKey) {
+	t.Helper()
+
+	previousAlgorithm, previousSecret := config.JWTAlgorithm, config.SecretKey
+	keySet.Lock()
+	previousKeys, previousSigning := keySet.keys, keySet.signing
+
+	config.JWTAlgorithm = algorithm
+	config.SecretKey = nil
+	keySet.keys = map[string]signingKey{}
+	keySet.signing = nil
+	for _, key := range keys {
+		keySet.keys[key.id] = key
+		if key.method.Alg() == algorithm {
+			keySet.signing = append(keySet.signing, key)
+		}
+	}
+	//com loadedAt recente findKey não tenta recarregar as chaves do banco
+	keySet.loadedAt = time.Now()
+	keySet.Unlock()
+
+	t.Cleanup(func() {
+		keySet.Lock()
+		keySet.keys, keySet.signing = previousKeys, previousSigning
+		keySet.Unlock()
+		config.JWTAlgorithm, config.SecretKey = previousAlgorithm, previousSecret
+	})
+}
+
+func TestCurrentKey(t *testing.T) {
+	now := time.Now()
+	old := newTestKey(t, "ES256", now.Add(-time.Hour))
+	active := newTestKey(t, "ES256", now.Add(-time.Minute))
+	pending := newTestKey(t, "ES256", now.Add(keyPublishDelay))
+
+	tests := []struct {
+		name    string
+		keys    []signingKey
+		want    string
+		wantErr bool
+	}{
+		{"a mais nova que já assina", []signingKey{active, old}, active.id, false},
+		{"a publicada ainda não assina", []signingKey{pending, active, old}, active.id, false},
+		{"só a publicada", []signingKey{pending}, "", true},
+		{"nenhuma chave", nil, "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			useKeys(t, "ES256", test.keys...)
+
+			key, err := currentKey()
+			if (err != nil) != test.wantErr {
+				t.Fatalf("currentKey() erro = %v, esperado erro: %v", err, test.wantErr)
+			}
+			if key.id != test.want {
+				t.Errorf("currentKey() = %s, esperado %s", key.id, test.want)
+			}
+		})
+	}
+}
+
+func TestParseTokenString(t *testing.T) {
+	now := time.Now()
+	signer := newTestKey(t, "EdDSA", now.Add(-time.Minute))
+	other := newTestKey(t, "ES256", now.Add(-time.Minute))
+	unknown := newTestKey(t, "EdDSA", now.Add(-time.Minute))
+	useKeys(t, "EdDSA", signer, other)
+
+	claims := func() jwt.MapClaims {
+		return jwt.MapClaims{"userID": 1, "exp": now.Add(time.Hour).Unix()}
+	}
+
+	sign := func(key signingKey, kid string, permissions jwt.MapClaims) string {
+		token := jwt.NewWithClaims(key.method, permissions)
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key.private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	valid, err := signToken(claims())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expired := claims()
+	expired["exp"] = now.Add(-time.Minute).Unix()
+
+	hmac, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims()).SignedString([]byte("segredo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	none, err := jwt.NewWithClaims(jwt.SigningMethodNone, claims()).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr bool
+	}{
+		{"assinado com a chave atual", valid, false},
+		{"assinado com outra chave publicada", sign(other, other.id, claims()), false},
+		{"kid desconhecido", sign(unknown, unknown.id, claims()), true},
+		{"kid de outra chave", sign(unknown, signer.id, claims()), true},
+		{"algoritmo diferente do da chave", sign(other, signer.id, claims()), true},
+		{"expirado", sign(signer, signer.id, expired), true},
+		{"HMAC sem SecretKey configurada", hmac, true},
+		{"sem assinatura", none, true},
+		{"vazio", "", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if _, err := parseTokenString(test.token); (err != nil) != test.wantErr {
+				t.Errorf("parseTokenString() erro = %v, esperado erro: %v", err, test.wantErr)
+			}
+		})
+	}
+}
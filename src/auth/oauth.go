@@ -0,0 +1,47 @@
+package auth
+
+import (
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"strings"
+	"time"
+
+	jwt "github.com/dgrijalva/jwt-go"
+)
+
+//OAuthTokenTTL é a validade dos access tokens entregues a aplicativos de terceiros
+const OAuthTokenTTL = time.Hour
+
+//CreateScopedToken gera o access token de um aplicativo de terceiros, limitado aos escopos autorizados pelo usuário
+func CreateScopedToken(userID uint64, clientID string, scopes []string) (string, error) {
+	tokenID, err := NewOpaqueToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	permissions := jwt.MapClaims{}
+	permissions["authorized"] = true
+	permissions["jti"] = tokenID
+	permissions["iat"] = now.Unix()
+	permissions["exp"] = now.Add(OAuthTokenTTL).Unix()
+	permissions["userID"] = userID
+	permissions["client_id"] = clientID
+	permissions["scope"] = strings.Join(scopes, " ")
+
+	return signToken(permissions)
+}
+
+//VerifyCodeChallenge confere o code_verifier do PKCE com o code_challenge recebido na autorização.
+//Só o método S256 é aceito, já que o plain não protege contra a interceptação do código.
+func VerifyCodeChallenge(verifier, challenge, method string) bool {
+	if method != "S256" || len(verifier) < 43 || len(verifier) > 128 {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(verifier))
+	expected := base64.RawURLEncoding.EncodeToString(sum[:])
+
+	return subtle.ConstantTimeCompare([]byte(expected), []byte(challenge)) == 1
+}
@@ -0,0 +1,36 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	//par gerado à parte: base64url(sha256(verifier)), sem padding
+	verifier := "dBjftJeZ4CVP-mJ92K9qkArR-vAxvEHtWGBr3EJd1nk"
+	challenge := "URz_5mleIBPKEWjPirN6iMD3Jfab32f-iggFcfw-xjk"
+
+	tests := []struct {
+		name                string
+		verifier, challenge string
+		method              string
+		want                bool
+	}{
+		{"verifier correto", verifier, challenge, "S256", true},
+		{"verifier errado", strings.Replace(verifier, "d", "e", 1), challenge, "S256", false},
+		{"challenge com padding", verifier, challenge + "=", "S256", false},
+		{"método plain", verifier, verifier, "plain", false},
+		{"sem método", verifier, challenge, "", false},
+		{"verifier curto demais", verifier[:42], challenge, "S256", false},
+		{"verifier longo demais", strings.Repeat("a", 129), challenge, "S256", false},
+		{"sem challenge", verifier, "", "S256", false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := VerifyCodeChallenge(test.verifier, test.challenge, test.method); got != test.want {
+				t.Errorf("VerifyCodeChallenge() = %v, esperado %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"api/src/db"
+	"api/src/repositories"
+	"errors"
+	"strings"
+)
+
+//personalAccessTokenPrefix diferencia os personal access tokens dos JWTs no header Authorization
+const personalAccessTokenPrefix = "dbp_"
+
+//NewPersonalAccessToken gera um token e o prefixo que é mostrado ao usuário para identificá-lo depois
+func NewPersonalAccessToken() (string, string, error) {
+	random, err := NewOpaqueToken(32)
+	if err != nil {
+		return "", "", err
+	}
+
+	token := personalAccessTokenPrefix + random
+	return token, token[:len(personalAccessTokenPrefix)+8], nil
+}
+
+func isPersonalAccessToken(token string) bool {
+	return strings.HasPrefix(token, personalAccessTokenPrefix)
+}
+
+func personalAccessTokenClaims(token string) (Claims, error) {
+	db, err := db.ConnectDB()
+	if err != nil {
+		return Claims{}, err
+	}
+	defer db.Close()
+
+	rep := repositories.NewPersonalAccessTokenRep(db)
+	storedToken, err := rep.SearchActiveByHash(HashToken(token))
+	if err != nil {
+		return Claims{}, err
+	}
+
+	if storedToken.ID == 0 {
+		return Claims{}, errors.New("invalid token")
+	}
+
+	if err = rep.Touch(storedToken.ID); err != nil {
+		return Claims{}, err
+	}
+
+	return Claims{
+		UserID:                storedToken.UserID,
+		Scopes:                storedToken.Scopes,
+		Scoped:                true,
+		PersonalAccessTokenID: storedToken.ID,
+	}, nil
+}
@@ -2,6 +2,7 @@ package auth
 
 import (
 	"api/src/config"
+	"context"
 	"errors"
 	"fmt"
 	"net/http"
@@ -12,28 +13,71 @@ import (
 	jwt "github.com/dgrijalva/jwt-go"
 )
 
-func CreateToken(userID uint64) (string, error) {
+//Claims são os dados do usuário e do próprio token extraídos de um JWT válido
+type Claims struct {
+	UserID    uint64
+	Roles     []string
+	TokenID   string
+	IssuedAt  time.Time
+	ExpiresAt time.Time
+
+	//Scoped indica que o token só pode acessar rotas cujos escopos estejam todos em Scopes
+	Scoped bool
+	Scopes []string
+	//PersonalAccessTokenID é preenchido quando a requisição usa um personal access token em vez de um JWT
+	PersonalAccessTokenID uint64
+	//ClientID é o aplicativo de terceiros que recebeu o token pelo fluxo OAuth
+	ClientID string
+	//SessionID é a sessão (o login em um dispositivo) que emitiu o token
+	SessionID uint64
+}
+
+type contextKey struct{}
+
+//WithClaims guarda os dados do token já validado na requisição, para que os controllers não validem de novo
+func WithClaims(r *http.Request, claims Claims) *http.Request {
+	return r.WithContext(context.WithValue(r.Context(), contextKey{}, claims))
+}
+
+func CreateToken(userID, sessionID uint64, roles []string) (string, error) {
+	tokenID, err := NewOpaqueToken(16)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
 	permissions := jwt.MapClaims{}
 	permissions["authorized"] = true
-	permissions["exp"] = time.Now().Add(time.Hour * 6).Unix()
+	permissions["jti"] = tokenID
+	permissions["iat"] = now.Unix()
+	permissions["exp"] = now.Add(time.Hour * 6).Unix()
 	permissions["userID"] = userID
+	permissions["sid"] = sessionID
+	permissions["roles"] = roles
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissions)
-	return token.SignedString([]byte(config.SecretKey))
+	return signToken(permissions)
 }
 
-func ValidateToken(r *http.Request) error {
-	tokenString := getToken(r)
-	token, err := jwt.Parse(tokenString, getVerifyKey)
-	if err != nil {
-		return err
+//signToken assina as permissões com o algoritmo e a chave atuais
+func signToken(permissions jwt.MapClaims) (string, error) {
+	if !isAsymmetric(config.JWTAlgorithm) {
+		token := jwt.NewWithClaims(jwt.SigningMethodHS256, permissions)
+		return token.SignedString([]byte(config.SecretKey))
 	}
 
-	if _, ok := token.Claims.(jwt.MapClaims); ok && token.Valid{
-		return nil
+	key, err := currentKey()
+	if err != nil {
+		return "", err
 	}
 
-	return errors.New("invalid token")
+	token := jwt.NewWithClaims(key.method, permissions)
+	token.Header["kid"] = key.id
+	return token.SignedString(key.private)
+}
+
+func ValidateToken(r *http.Request) error {
+	_, err := parseToken(r)
+	return err
 }
 
 func getToken(r *http.Request) string {
@@ -46,29 +90,150 @@ func getToken(r *http.Request) string {
 	return ""
 }
 
+//getVerifyKey escolhe a chave pelo kid do header. Tokens sem kid são os assinados com HMAC pela SecretKey,
+//que continuam aceitos enquanto ela estiver configurada.
 func getVerifyKey(token *jwt.Token) (interface{}, error) {
-	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok{
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok || len(config.SecretKey) == 0 {
+			return nil, fmt.Errorf("método de assinatura inesperado %v", token.Header["alg"])
+		}
+
+		return config.SecretKey, nil
+	}
+
+	key, ok := findKey(kid)
+	if !ok {
+		return nil, fmt.Errorf("chave de assinatura desconhecida %s", kid)
+	}
+
+	if token.Method.Alg() != key.method.Alg() {
 		return nil, fmt.Errorf("método de assinatura inesperado %v", token.Header["alg"])
 	}
 
-	return config.SecretKey, nil
+	return key.public, nil
 }
 
-func GetUserID(r *http.Request) (uint64, error) {
-	tokenString := getToken(r)
+//parseToken valida o access token do header; tokens com outra finalidade, como o desafio do 2FA, são recusados
+func parseToken(r *http.Request) (jwt.MapClaims, error) {
+	permissions, err := parseTokenString(getToken(r))
+	if err != nil {
+		return nil, err
+	}
+
+	if purpose, ok := permissions["purpose"]; ok && purpose != "" {
+		return nil, errors.New("invalid token")
+	}
+
+	return permissions, nil
+}
+
+func parseTokenString(tokenString string) (jwt.MapClaims, error) {
 	token, err := jwt.Parse(tokenString, getVerifyKey)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
 	if permissions, ok := token.Claims.(jwt.MapClaims); ok && token.Valid{
-		userID, err := strconv.ParseUint(fmt.Sprintf("%.0f", permissions["userID"]), 10, 64)
-		if err != nil {
-			return 0, err
+		return permissions, nil
+	}
+
+	return nil, errors.New("invalid token")
+}
+
+//GetClaims retorna os dados do token enviado no header Authorization, seja ele um JWT ou um personal access token
+func GetClaims(r *http.Request) (Claims, error) {
+	if claims, ok := r.Context().Value(contextKey{}).(Claims); ok {
+		return claims, nil
+	}
+
+	if tokenString := getToken(r); isPersonalAccessToken(tokenString) {
+		return personalAccessTokenClaims(tokenString)
+	}
+
+	permissions, err := parseToken(r)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	userID, err := strconv.ParseUint(fmt.Sprintf("%.0f", permissions["userID"]), 10, 64)
+	if err != nil {
+		return Claims{}, err
+	}
+
+	claims := Claims{UserID: userID}
+	claims.TokenID, _ = permissions["jti"].(string)
+	if issuedAt, ok := permissions["iat"].(float64); ok {
+		claims.IssuedAt = time.Unix(int64(issuedAt), 0)
+	}
+	if expiresAt, ok := permissions["exp"].(float64); ok {
+		claims.ExpiresAt = time.Unix(int64(expiresAt), 0)
+	}
+	if sessionID, ok := permissions["sid"].(float64); ok {
+		claims.SessionID = uint64(sessionID)
+	}
+	if roles, ok := permissions["roles"].([]interface{}); ok {
+		for _, role := range roles {
+			if name, ok := role.(string); ok {
+				claims.Roles = append(claims.Roles, name)
+			}
+		}
+	}
+	if scope, ok := permissions["scope"].(string); ok {
+		claims.Scoped = true
+		claims.Scopes = strings.Fields(scope)
+		claims.ClientID, _ = permissions["client_id"].(string)
+	}
+
+	return claims, nil
+}
+
+//HasAnyRole indica se o token pertence a um usuário com pelo menos um dos papéis informados
+func (c Claims) HasAnyRole(roles ...string) bool {
+	for _, role := range roles {
+		for _, userRole := range c.Roles {
+			if role == userRole {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+//HasScopes indica se o token pode acessar uma rota que exige os escopos informados.
+//Tokens sem escopo (o login normal) podem tudo; tokens com escopo não acessam rotas que não declaram escopos.
+func (c Claims) HasScopes(required ...string) bool {
+	if !c.Scoped {
+		return true
+	}
+
+	if len(required) == 0 {
+		return false
+	}
+
+	for _, scope := range required {
+		found := false
+		for _, granted := range c.Scopes {
+			if scope == granted {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
 		}
-		
-		return userID, nil
 	}
 
-	return 0, errors.New("invalid token")
-}
\ No newline at end of file
+	return true
+}
+
+func GetUserID(r *http.Request) (uint64, error) {
+	claims, err := GetClaims(r)
+	if err != nil {
+		return 0, err
+	}
+
+	return claims.UserID, nil
+}
@@ -0,0 +1,33 @@
+package auth
+
+import "testing"
+
+func TestHasScopes(t *testing.T) {
+	login := Claims{}
+	scoped := Claims{Scoped: true, Scopes: []string{"posts:read", "posts:write"}}
+	empty := Claims{Scoped: true}
+
+	tests := []struct {
+		name     string
+		claims   Claims
+		required []string
+		want     bool
+	}{
+		{"login normal em rota sem escopos", login, nil, true},
+		{"login normal em rota com escopos", login, []string{"posts:write"}, true},
+		{"token com escopo em rota sem escopos", scoped, nil, false},
+		{"token com o escopo exigido", scoped, []string{"posts:read"}, true},
+		{"token com todos os escopos exigidos", scoped, []string{"posts:read", "posts:write"}, true},
+		{"token sem um dos escopos exigidos", scoped, []string{"posts:read", "users:write"}, false},
+		{"token sem escopos", empty, []string{"posts:read"}, false},
+		{"token sem escopos em rota sem escopos", empty, nil, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.claims.HasScopes(test.required...); got != test.want {
+				t.Errorf("HasScopes(%v) = %v, esperado %v", test.required, got, test.want)
+			}
+		})
+	}
+}
@@ -5,6 +5,8 @@ import (
 	"log"
 	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +15,81 @@ var (
 	ConnectDB = ""
 	Port      = 0
 	SecretKey []byte
+
+	//RefreshTokenTTL é o tempo de vida de um refresh token que não foi usado
+	RefreshTokenTTL time.Duration
+
+	//JWTAlgorithm é o algoritmo usado para assinar os tokens: HS256 (com a SecretKey), RS256, ES256 ou EdDSA
+	JWTAlgorithm = ""
+	//KeyRotationInterval é o tempo que uma chave assimétrica fica assinando tokens antes de ser substituída
+	KeyRotationInterval time.Duration
+	//KeyRotationOverlap é por quanto tempo uma chave substituída ainda verifica tokens, deve ser maior que a validade do token
+	KeyRotationOverlap time.Duration
+
+	//AppURL é o endereço do front-end, usado nos links enviados por email
+	AppURL = ""
+	//Mailer escolhe como os emails são entregues: "smtp" ou "file" (padrão, grava os emails em MailDir)
+	Mailer       = ""
+	MailFrom     = ""
+	MailDir      = ""
+	SMTPHost     = ""
+	SMTPPort     = 0
+	SMTPUser     = ""
+	SMTPPassword = ""
+
+	PasswordResetTTL time.Duration
+	MagicLinkTTL     time.Duration
+
+	//APIURL é o endereço público desta API, usado nos links que apontam direto para ela
+	APIURL = ""
+	//EmailVerificationPolicy define o que exige email confirmado: "none" (padrão), "login" ou "post"
+	EmailVerificationPolicy = ""
+	EmailVerificationTTL    time.Duration
+
+	//LoginTracker define onde ficam as tentativas de login que falharam: "memory" (padrão) ou "mysql"
+	LoginTracker = ""
+	//LoginLockoutThreshold é o número de falhas seguidas que bloqueia a conta por LoginLockoutDuration
+	LoginLockoutThreshold = 0
+	LoginLockoutDuration  time.Duration
+	//TrustProxy faz a API usar o header X-Forwarded-For como IP do cliente; só ative atrás de um proxy confiável
+	TrustProxy = false
+
+	PasswordMinLength = 0
+	//PasswordRequiredClasses são as classes de caracteres exigidas nas senhas: lower, upper, digit e symbol
+	PasswordRequiredClasses []string
+	//PasswordBreachList é o arquivo com os SHA-1 de senhas vazadas; vazio desliga a verificação
+	PasswordBreachList = ""
+
+	//PasswordHashAlgorithm é o algoritmo dos hashes de senha novos: "argon2id" (padrão) ou "bcrypt".
+	//Os hashes antigos são refeitos com o algoritmo e os parâmetros atuais no próximo login.
+	PasswordHashAlgorithm = ""
+	//Argon2Memory é a memória usada pelo argon2id, em KiB
+	Argon2Memory      uint32
+	Argon2Iterations  uint32
+	Argon2Parallelism uint8
+	BcryptCost        = 0
+
+	//TrendingWindow é o período considerado na contagem dos trending topics
+	TrendingWindow time.Duration
+
+	//PublishInterval é de quanto em quanto tempo o agendador procura publicações agendadas que já devem sair
+	PublishInterval time.Duration
+
+	//TrashRetention é por quanto tempo usuários e publicações excluídos ainda podem ser restaurados antes de sumirem de vez
+	TrashRetention time.Duration
+
+	//MediaStorage escolhe onde os anexos ficam: "local" (padrão, arquivos em MediaDir) ou "s3", qualquer serviço compatível com S3
+	MediaStorage = ""
+	MediaDir     = ""
+	//MediaURL é o endereço base dos anexos; por padrão a própria API os serve em /media
+	MediaURL = ""
+	//MediaMaxBytes é o tamanho máximo de cada arquivo enviado
+	MediaMaxBytes int64
+	S3Endpoint    = ""
+	S3Region      = ""
+	S3Bucket      = ""
+	S3AccessKey   = ""
+	S3SecretKey   = ""
 )
 
 func Load() {
@@ -30,4 +107,182 @@ func Load() {
 	os.Getenv("DB_DATABASE"))
 
 	SecretKey = []byte(os.Getenv("SECRET_KEY"))
-}
\ No newline at end of file
+
+	refreshHours, err := strconv.Atoi(os.Getenv("REFRESH_TOKEN_TTL_HOURS"))
+	if err != nil {
+		refreshHours = 24 * 30
+	}
+	RefreshTokenTTL = time.Duration(refreshHours) * time.Hour
+
+	JWTAlgorithm = os.Getenv("JWT_ALGORITHM")
+	if JWTAlgorithm == "" {
+		JWTAlgorithm = "HS256"
+	}
+
+	switch JWTAlgorithm {
+	case "HS256", "RS256", "ES256", "EdDSA":
+	default:
+		log.Fatalf("JWT_ALGORITHM não suportado %s, use HS256, RS256, ES256 ou EdDSA", JWTAlgorithm)
+	}
+
+	rotationHours, err := strconv.Atoi(os.Getenv("JWT_KEY_ROTATION_HOURS"))
+	if err != nil {
+		rotationHours = 24 * 30
+	}
+	KeyRotationInterval = time.Duration(rotationHours) * time.Hour
+
+	overlapHours, err := strconv.Atoi(os.Getenv("JWT_KEY_OVERLAP_HOURS"))
+	if err != nil {
+		overlapHours = 24
+	}
+	KeyRotationOverlap = time.Duration(overlapHours) * time.Hour
+
+	AppURL = os.Getenv("APP_URL")
+	if AppURL == "" {
+		AppURL = "http://localhost:3000"
+	}
+
+	Mailer = os.Getenv("MAILER")
+	MailFrom = os.Getenv("MAIL_FROM")
+	if MailFrom == "" {
+		MailFrom = "DevBook <no-reply@devbook.local>"
+	}
+	MailDir = os.Getenv("MAIL_DIR")
+	if MailDir == "" {
+		MailDir = "mails"
+	}
+	SMTPHost = os.Getenv("SMTP_HOST")
+	SMTPPort, err = strconv.Atoi(os.Getenv("SMTP_PORT"))
+	if err != nil {
+		SMTPPort = 587
+	}
+	SMTPUser = os.Getenv("SMTP_USER")
+	SMTPPassword = os.Getenv("SMTP_PASSWORD")
+
+	resetMinutes, err := strconv.Atoi(os.Getenv("PASSWORD_RESET_TTL_MINUTES"))
+	if err != nil {
+		resetMinutes = 60
+	}
+	PasswordResetTTL = time.Duration(resetMinutes) * time.Minute
+
+	magicLinkMinutes, err := strconv.Atoi(os.Getenv("MAGIC_LINK_TTL_MINUTES"))
+	if err != nil {
+		magicLinkMinutes = 15
+	}
+	MagicLinkTTL = time.Duration(magicLinkMinutes) * time.Minute
+
+	APIURL = os.Getenv("API_URL")
+	if APIURL == "" {
+		APIURL = fmt.Sprintf("http://localhost:%d", Port)
+	}
+
+	EmailVerificationPolicy = os.Getenv("EMAIL_VERIFICATION_POLICY")
+	if EmailVerificationPolicy == "" {
+		EmailVerificationPolicy = "none"
+	}
+
+	verificationHours, err := strconv.Atoi(os.Getenv("EMAIL_VERIFICATION_TTL_HOURS"))
+	if err != nil {
+		verificationHours = 48
+	}
+	EmailVerificationTTL = time.Duration(verificationHours) * time.Hour
+
+	LoginTracker = os.Getenv("LOGIN_TRACKER")
+	LoginLockoutThreshold, err = strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_THRESHOLD"))
+	if err != nil || LoginLockoutThreshold <= 0 {
+		LoginLockoutThreshold = 10
+	}
+
+	lockoutMinutes, err := strconv.Atoi(os.Getenv("LOGIN_LOCKOUT_MINUTES"))
+	if err != nil {
+		lockoutMinutes = 15
+	}
+	LoginLockoutDuration = time.Duration(lockoutMinutes) * time.Minute
+
+	TrustProxy, _ = strconv.ParseBool(os.Getenv("TRUST_PROXY"))
+
+	PasswordMinLength, err = strconv.Atoi(os.Getenv("PASSWORD_MIN_LENGTH"))
+	if err != nil || PasswordMinLength <= 0 {
+		PasswordMinLength = 8
+	}
+
+	PasswordRequiredClasses = nil
+	for _, class := range strings.Split(os.Getenv("PASSWORD_REQUIRED_CLASSES"), ",") {
+		if class = strings.TrimSpace(class); class != "" {
+			PasswordRequiredClasses = append(PasswordRequiredClasses, class)
+		}
+	}
+
+	PasswordBreachList = os.Getenv("PASSWORD_BREACH_LIST")
+
+	PasswordHashAlgorithm = os.Getenv("PASSWORD_HASH_ALGORITHM")
+	if PasswordHashAlgorithm == "" {
+		PasswordHashAlgorithm = "argon2id"
+	}
+
+	argon2Memory, err := strconv.ParseUint(os.Getenv("ARGON2_MEMORY_KB"), 10, 32)
+	if err != nil {
+		argon2Memory = 64 * 1024
+	}
+	Argon2Memory = uint32(argon2Memory)
+
+	argon2Iterations, err := strconv.ParseUint(os.Getenv("ARGON2_ITERATIONS"), 10, 32)
+	if err != nil {
+		argon2Iterations = 3
+	}
+	Argon2Iterations = uint32(argon2Iterations)
+
+	argon2Parallelism, err := strconv.ParseUint(os.Getenv("ARGON2_PARALLELISM"), 10, 8)
+	if err != nil {
+		argon2Parallelism = 2
+	}
+	Argon2Parallelism = uint8(argon2Parallelism)
+
+	BcryptCost, err = strconv.Atoi(os.Getenv("BCRYPT_COST"))
+	if err != nil {
+		BcryptCost = 10
+	}
+
+	trendingHours, err := strconv.Atoi(os.Getenv("HASHTAG_TRENDING_HOURS"))
+	if err != nil || trendingHours <= 0 {
+		trendingHours = 24
+	}
+	TrendingWindow = time.Duration(trendingHours) * time.Hour
+
+	publishSeconds, err := strconv.Atoi(os.Getenv("SCHEDULER_INTERVAL_SECONDS"))
+	if err != nil || publishSeconds <= 0 {
+		publishSeconds = 30
+	}
+	PublishInterval = time.Duration(publishSeconds) * time.Second
+
+	trashDays, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil || trashDays <= 0 {
+		trashDays = 30
+	}
+	TrashRetention = time.Duration(trashDays) * 24 * time.Hour
+
+	MediaStorage = os.Getenv("MEDIA_STORAGE")
+	MediaDir = os.Getenv("MEDIA_DIR")
+	if MediaDir == "" {
+		MediaDir = "media"
+	}
+
+	MediaURL = strings.TrimSuffix(os.Getenv("MEDIA_URL"), "/")
+	if MediaURL == "" {
+		MediaURL = APIURL + "/media"
+	}
+
+	MediaMaxBytes, err = strconv.ParseInt(os.Getenv("MEDIA_MAX_BYTES"), 10, 64)
+	if err != nil || MediaMaxBytes <= 0 {
+		MediaMaxBytes = 10 << 20
+	}
+
+	S3Endpoint = strings.TrimSuffix(os.Getenv("S3_ENDPOINT"), "/")
+	S3Region = os.Getenv("S3_REGION")
+	if S3Region == "" {
+		S3Region = "us-east-1"
+	}
+	S3Bucket = os.Getenv("S3_BUCKET")
+	S3AccessKey = os.Getenv("S3_ACCESS_KEY_ID")
+	S3SecretKey = os.Getenv("S3_SECRET_ACCESS_KEY")
+}
@@ -0,0 +1,171 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+func AssignRole(w http.ResponseWriter, r *http.Request) {
+	actorID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewRoleRep(db)
+	exists, err := rep.Exists(params["role"])
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !exists {
+		response.Erro(w, http.StatusNotFound, errors.New("papel não encontrado"))
+		return
+	}
+
+	user, err := repositories.NewUserRep(db).GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if user.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+		return
+	}
+
+	if err := audit(db, actorID, "assign_role:"+params["role"], "user", userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = rep.Assign(userID, params["role"]); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	//como na remoção, os tokens atuais ainda carregam os papéis antigos; o próximo refresh já vem com o novo
+	if err := repositories.NewUserRep(db).RevokeTokens(userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//canOverride confere se quem age pode mexer na conta de outro usuário, já que moderadores não podem agir sobre
+//administradores nem sobre outros moderadores. Escreve o erro na resposta quando não pode.
+func canOverride(w http.ResponseWriter, db *sql.DB, claims auth.Claims, targetID uint64) bool {
+	if targetID == claims.UserID {
+		return true
+	}
+
+	targetRoles, err := repositories.NewRoleRep(db).SearchByUser(targetID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	if !models.CanOverride(claims.Roles, targetRoles) {
+		response.Erro(w, http.StatusForbidden, errors.New("você não tem permissão para alterar esta conta"))
+		return false
+	}
+
+	return true
+}
+
+func RemoveRole(w http.ResponseWriter, r *http.Request) {
+	actorID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if err := audit(db, actorID, "remove_role:"+params["role"], "user", userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := repositories.NewRoleRep(db).Remove(userID, params["role"]); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	//os access tokens atuais ainda carregam o papel removido; o próximo refresh já vem sem ele
+	if err := repositories.NewUserRep(db).RevokeTokens(userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+func GetAuditLogs(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 || limit > 500 {
+		limit = 100
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	logs, err := repositories.NewAuditLogRep(db).Search(limit)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, logs)
+}
+
+//audit registra uma ação administrativa; é chamado antes da ação para que nenhuma fique sem registro
+func audit(db *sql.DB, actorID uint64, action, targetType string, targetID uint64) error {
+	return repositories.NewAuditLogRep(db).Create(models.AuditLog{
+		ActorID:    actorID,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetID,
+	})
+}
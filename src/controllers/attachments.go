@@ -0,0 +1,322 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/config"
+	"api/src/db"
+	"api/src/media"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"api/src/storage"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//UploadAttachments anexa à publicação as imagens enviadas em multipart/form-data no campo files.
+//Só o autor anexa arquivos, e se algum arquivo for recusado nenhum dos enviados na requisição fica salvo.
+func UploadAttachments(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["postID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !canPost(w, db, userID) {
+		return
+	}
+
+	post, err := repositories.NewPostRep(db).GetOnePost(postID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if post.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	if post.AuthorID != userID {
+		response.Erro(w, http.StatusForbidden, errors.New("só o autor pode anexar arquivos à publicação"))
+		return
+	}
+
+	if post.Kind == models.PostKindRepost {
+		response.Erro(w, http.StatusBadRequest, errors.New("um repost não pode ter anexos"))
+		return
+	}
+
+	//folga de 1 MiB por arquivo para os cabeçalhos do multipart
+	r.Body = http.MaxBytesReader(w, r.Body, models.MaxAttachments*(config.MediaMaxBytes+1<<20))
+	reader, err := r.MultipartReader()
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, errors.New("envie os arquivos como multipart/form-data"))
+		return
+	}
+
+	rep := repositories.NewAttachmentRep(db)
+	var created []models.Attachment
+	saved := false
+	defer func() {
+		if !saved {
+			for _, attachment := range created {
+				removeAttachment(rep, attachment)
+			}
+		}
+	}()
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			response.Erro(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if part.FormName() != "files" {
+			continue
+		}
+
+		//só evita processar imagens que não caberiam; o limite vale de fato no insert
+		if len(post.Attachments)+len(created) >= models.MaxAttachments {
+			response.Erro(w, http.StatusBadRequest, errTooManyAttachments)
+			return
+		}
+
+		data, err := io.ReadAll(io.LimitReader(part, config.MediaMaxBytes+1))
+		if err != nil {
+			response.Erro(w, http.StatusBadRequest, err)
+			return
+		}
+
+		if int64(len(data)) > config.MediaMaxBytes {
+			response.Erro(w, http.StatusRequestEntityTooLarge, fmt.Errorf("cada arquivo pode ter no máximo %d bytes", config.MediaMaxBytes))
+			return
+		}
+
+		image, err := media.Process(data)
+		if errors.Is(err, media.ErrUnsupported) {
+			response.Erro(w, http.StatusUnsupportedMediaType, err)
+			return
+		}
+		if err != nil {
+			response.Erro(w, http.StatusBadRequest, err)
+			return
+		}
+
+		attachment, err := storeAttachment(rep, postID, image)
+		if errors.Is(err, errTooManyAttachments) {
+			response.Erro(w, http.StatusBadRequest, err)
+			return
+		}
+		if err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		created = append(created, attachment)
+	}
+
+	if len(created) == 0 {
+		response.Erro(w, http.StatusBadRequest, errors.New("nenhum arquivo enviado no campo files"))
+		return
+	}
+
+	saved = true
+	response.JSON(w, http.StatusCreated, created)
+}
+
+//DeleteAttachment remove o anexo da publicação; o autor remove os seus, admins e moderadores os de qualquer um
+func DeleteAttachment(w http.ResponseWriter, r *http.Request) {
+	claims, err := auth.GetClaims(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["postID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	attachmentID, err := strconv.ParseUint(params["attachmentID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	post, err := repositories.NewPostRep(db).GetOnePost(postID, claims.UserID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	rep := repositories.NewAttachmentRep(db)
+	attachment, err := rep.GetByID(postID, attachmentID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if post.ID == 0 || attachment.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("anexo não encontrado"))
+		return
+	}
+
+	if post.AuthorID != claims.UserID {
+		if !claims.HasAnyRole(models.RoleAdmin, models.RoleModerator) {
+			response.Erro(w, http.StatusForbidden, errors.New("não é possível remover anexos de uma publicação que não seja sua"))
+			return
+		}
+
+		if err := audit(db, claims.UserID, "delete_attachment", "attachment", attachmentID); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err = removeAttachment(rep, attachment); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//GetMedia entrega um arquivo guardado no armazenamento. As chaves são aleatórias e nunca mudam de conteúdo,
+//então o arquivo pode ficar em cache para sempre.
+func GetMedia(w http.ResponseWriter, r *http.Request) {
+	key := mux.Vars(r)["key"]
+	if !storage.ValidKey(key) {
+		response.Erro(w, http.StatusNotFound, storage.ErrNotFound)
+		return
+	}
+
+	file, err := storage.Default.Get(key)
+	if errors.Is(err, storage.ErrNotFound) {
+		response.Erro(w, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer file.Close()
+
+	w.Header().Set("Content-Type", mime.TypeByExtension(path.Ext(key)))
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Set("Cache-Control", "public, max-age=31536000, immutable")
+	if _, err = io.Copy(w, file); err != nil {
+		log.Printf("erro ao enviar o arquivo %s: %v", key, err)
+	}
+}
+
+//setPostURLs monta os endereços das imagens das publicações; os repositórios devolvem só as chaves do armazenamento
+func setPostURLs(posts []models.Post) {
+	for i := range posts {
+		posts[i].SetURLs(storage.URL)
+	}
+}
+
+var errTooManyAttachments = fmt.Errorf("uma publicação pode ter no máximo %d anexos", models.MaxAttachments)
+
+//storeAttachment guarda a imagem e a miniatura no armazenamento e registra o anexo; retorna errTooManyAttachments se a publicação encheu
+func storeAttachment(rep *repositories.Attachments, postID uint64, image media.Image) (models.Attachment, error) {
+	name := make([]byte, 16)
+	if _, err := rand.Read(name); err != nil {
+		return models.Attachment{}, err
+	}
+
+	prefix := fmt.Sprintf("posts/%d/%s", postID, hex.EncodeToString(name))
+	attachment := models.Attachment{
+		PostID:       postID,
+		Key:          prefix + "." + image.Extension,
+		ThumbnailKey: prefix + "-thumb." + image.Thumbnail.Extension,
+		ContentType:  image.ContentType,
+		Size:         int64(len(image.Data)),
+		Width:        image.Width,
+		Height:       image.Height,
+	}
+
+	if err := storage.Default.Put(attachment.Key, bytes.NewReader(image.Data), attachment.Size, image.ContentType); err != nil {
+		return models.Attachment{}, err
+	}
+
+	thumbnail := image.Thumbnail
+	if err := storage.Default.Put(attachment.ThumbnailKey, bytes.NewReader(thumbnail.Data), int64(len(thumbnail.Data)), thumbnail.ContentType); err != nil {
+		deleteFiles(attachment)
+		return models.Attachment{}, err
+	}
+
+	id, err := rep.Create(attachment, models.MaxAttachments)
+	if err != nil {
+		deleteFiles(attachment)
+		return models.Attachment{}, err
+	}
+
+	//outro envio para a mesma publicação preencheu as vagas enquanto esta imagem era processada
+	if id == 0 {
+		deleteFiles(attachment)
+		return models.Attachment{}, errTooManyAttachments
+	}
+
+	attachment.ID = id
+	attachment.SetURLs(storage.URL)
+
+	return attachment, nil
+}
+
+//removeAttachment apaga o registro do anexo e depois os arquivos; um arquivo que não sair do armazenamento só fica órfão
+func removeAttachment(rep *repositories.Attachments, attachment models.Attachment) error {
+	if err := rep.Delete(attachment.ID); err != nil {
+		return err
+	}
+
+	deleteFiles(attachment)
+	return nil
+}
+
+func deleteFiles(attachment models.Attachment) {
+	for _, key := range []string{attachment.Key, attachment.ThumbnailKey} {
+		if err := storage.Default.Delete(key); err != nil {
+			log.Printf("erro ao apagar o arquivo %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//BookmarkPost salva a publicação para o usuário logado; só ele vê o que salvou
+func BookmarkPost(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["postID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !postExists(w, repositories.NewPostRep(db), postID, userID) {
+		return
+	}
+
+	if err = repositories.NewBookmarkRep(db).Add(userID, postID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+func UnbookmarkPost(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["postID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if err = repositories.NewBookmarkRep(db).Remove(userID, postID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//GetBookmarks lista as publicações salvas pelo usuário logado, das salvas por último para as mais antigas
+func GetBookmarks(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	cursor, limit, err := cursorPagination(r)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	posts, next, err := repositories.NewPostRep(db).SearchBookmarks(userID, cursor, limit)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	setPostURLs(posts)
+	page := models.BookmarkPage{Posts: posts}
+	if page.Posts == nil {
+		page.Posts = []models.Post{}
+	}
+	if next != 0 {
+		page.NextCursor = strconv.FormatUint(next, 10)
+	}
+
+	response.JSON(w, http.StatusOK, page)
+}
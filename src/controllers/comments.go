@@ -0,0 +1,311 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//NewComment comenta uma publicação ou, com parent_id, responde outro comentário da mesma publicação
+func NewComment(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["idPost"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var comment models.Comment
+	if err = json.Unmarshal(request, &comment); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = comment.Prepare(); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !canPost(w, db, userID) {
+		return
+	}
+
+	if !postExists(w, repositories.NewPostRep(db), postID, userID) {
+		return
+	}
+
+	rep := repositories.NewCommentRep(db)
+	if comment.ParentID != nil {
+		parent, err := rep.GetByID(postID, *comment.ParentID)
+		if err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if parent.ID == 0 {
+			response.Erro(w, http.StatusBadRequest, errors.New("o comentário respondido não existe nesta publicação"))
+			return
+		}
+	}
+
+	comment.PostID = postID
+	comment.AuthorID = userID
+	comment.ID, err = rep.Create(comment)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	comment, err = rep.GetByID(postID, comment.ID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusCreated, comment)
+}
+
+//GetComments lista os comentários da publicação página a página. Sem parent_id vêm os comentários de primeiro
+//nível e, com ele, as respostas àquele comentário; order=newest inverte a ordem padrão, dos mais antigos primeiro.
+func GetComments(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["idPost"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	limit, offset, err := pagination(r)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	query := r.URL.Query()
+
+	var newestFirst bool
+	switch query.Get("order") {
+	case "", "oldest":
+	case "newest":
+		newestFirst = true
+	default:
+		response.Erro(w, http.StatusBadRequest, errors.New("a ordem deve ser oldest ou newest"))
+		return
+	}
+
+	var parentID *uint64
+	if value := query.Get("parent_id"); value != "" {
+		id, err := strconv.ParseUint(value, 10, 64)
+		if err != nil {
+			response.Erro(w, http.StatusBadRequest, err)
+			return
+		}
+		parentID = &id
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !postExists(w, repositories.NewPostRep(db), postID, userID) {
+		return
+	}
+
+	comments, err := repositories.NewCommentRep(db).Search(postID, parentID, newestFirst, limit, offset)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, comments)
+}
+
+//UpdateComment edita o texto de um comentário; só quem o escreveu pode editá-lo
+func UpdateComment(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	postID, commentID, err := commentParams(r)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var comment models.Comment
+	if err = json.Unmarshal(request, &comment); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = comment.Prepare(); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !canPost(w, db, userID) {
+		return
+	}
+
+	if !postExists(w, repositories.NewPostRep(db), postID, userID) {
+		return
+	}
+
+	rep := repositories.NewCommentRep(db)
+	commentFromDB, err := rep.GetByID(postID, commentID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if commentFromDB.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("comentário não encontrado"))
+		return
+	}
+
+	if commentFromDB.AuthorID != userID {
+		response.Erro(w, http.StatusForbidden, errors.New("não é possivel editar um comentário que não seja seu"))
+		return
+	}
+
+	if err = rep.Update(commentID, comment.Content); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, nil)
+}
+
+//DeleteComment apaga o comentário e as respostas a ele. Além de quem o escreveu, o autor da publicação
+//e os moderadores também podem apagá-lo.
+func DeleteComment(w http.ResponseWriter, r *http.Request) {
+	claims, err := auth.GetClaims(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	postID, commentID, err := commentParams(r)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	postRep := repositories.NewPostRep(db)
+	if !postExists(w, postRep, postID, claims.UserID) {
+		return
+	}
+
+	rep := repositories.NewCommentRep(db)
+	comment, err := rep.GetByID(postID, commentID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if comment.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("comentário não encontrado"))
+		return
+	}
+
+	if comment.AuthorID != claims.UserID {
+		post, err := postRep.GetOnePost(postID, claims.UserID)
+		if err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if post.AuthorID != claims.UserID {
+			if !claims.HasAnyRole(models.RoleAdmin, models.RoleModerator) {
+				response.Erro(w, http.StatusForbidden, errors.New("não é possivel deletar um comentário que não seja seu"))
+				return
+			}
+
+			if err := audit(db, claims.UserID, "delete_comment", "comment", commentID); err != nil {
+				response.Erro(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+
+	if err = rep.Delete(commentID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, nil)
+}
+
+func commentParams(r *http.Request) (uint64, uint64, error) {
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["idPost"], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	commentID, err := strconv.ParseUint(params["commentId"], 10, 64)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return postID, commentID, nil
+}
@@ -0,0 +1,78 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/config"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//GetHashtagPosts lista as publicações com a hashtag, página a página. A hashtag pode vir com ou sem o #
+//e em qualquer combinação de maiúsculas e minúsculas.
+func GetHashtagPosts(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	tag := models.NormalizeHashtag(params["tag"])
+	if tag == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("hashtag inválida"))
+		return
+	}
+
+	limit, offset, err := pagination(r)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	posts, err := repositories.NewPostRep(db).SearchByHashtag(tag, userID, limit, offset)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	setPostURLs(posts)
+	response.JSON(w, http.StatusOK, posts)
+}
+
+//GetTrendingHashtags lista as hashtags mais usadas nas publicações feitas dentro de config.TrendingWindow
+func GetTrendingHashtags(w http.ResponseWriter, r *http.Request) {
+	limit, _, err := pagination(r)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	trending, err := repositories.NewHashtagRep(db).Trending(time.Now().Add(-config.TrendingWindow), limit)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, trending)
+}
@@ -0,0 +1,14 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/response"
+	"fmt"
+	"net/http"
+)
+
+//JWKS publica as chaves públicas para que outros serviços consigam verificar os tokens do DevBook
+func JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", int(auth.JWKSMaxAge.Seconds())))
+	response.JSON(w, http.StatusOK, auth.PublicKeys())
+}
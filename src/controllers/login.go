@@ -2,15 +2,22 @@ package controllers
 
 import (
 	"api/src/auth"
+	"api/src/config"
 	"api/src/db"
 	"api/src/models"
 	"api/src/repositories"
 	"api/src/response"
 	"api/src/security"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 )
 
 func Login(w http.ResponseWriter, r *http.Request){
@@ -26,6 +33,20 @@ func Login(w http.ResponseWriter, r *http.Request){
 		return
 	}
 
+	account := strings.ToLower(strings.TrimSpace(user.Email))
+	address := clientIP(r)
+
+	wait, err := reserveLogin(account, address)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if wait > 0 {
+		tooManyAttempts(w, wait)
+		return
+	}
+
 	db, err := db.ConnectDB()
 	if err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
@@ -46,13 +67,267 @@ func Login(w http.ResponseWriter, r *http.Request){
 		return
 	}
 
-	token, err := auth.CreateToken(hashedUser.ID)
+	if err = loginSucceeded(account, address); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	//a senha só existe em texto puro agora, então é a hora de refazer um hash com algoritmo ou parâmetros antigos
+	if security.NeedsRehash(hashedUser.Password) {
+		if err = rehashPassword(rep, hashedUser.ID, user.Password); err != nil {
+			log.Printf("erro ao atualizar o hash da senha do usuário %d: %v", hashedUser.ID, err)
+		}
+	}
+
+	if config.EmailVerificationPolicy == "login" && hashedUser.EmailVerifiedAt == nil {
+		response.Erro(w, http.StatusForbidden, errEmailNotVerified)
+		return
+	}
+
+	finishLogin(w, r, db, hashedUser.ID)
+}
+
+func rehashPassword(rep *repositories.Users, userID uint64, password string) error {
+	hashedPassword, err := security.Hash(password)
+	if err != nil {
+		return err
+	}
+
+	return rep.UpdatePassword(userID, string(hashedPassword))
+}
+
+//finishLogin conclui um login cujo primeiro fator já foi conferido: quem tem 2FA recebe um desafio,
+//os demais recebem os tokens de acesso
+func finishLogin(w http.ResponseWriter, r *http.Request, db *sql.DB, userID uint64) {
+	settings, err := repositories.NewTwoFactorRep(db).Get(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if settings.Enabled {
+		challenge, err := auth.CreateChallengeToken(userID)
+		if err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		response.JSON(w, http.StatusAccepted, models.TwoFactorChallenge{TwoFactorRequired: true, ChallengeToken: challenge})
+		return
+	}
+
+	authData, err := startSession(db, r, userID)
 	if err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
-	
-	userID := strconv.FormatUint(hashedUser.ID, 10)
 
-	response.JSON(w, http.StatusAccepted, models.AuthData{ID:userID,Token: token})
-}
\ No newline at end of file
+	response.JSON(w, http.StatusAccepted, authData)
+}
+
+//RefreshToken troca um refresh token válido por um novo par de tokens da mesma família
+func RefreshToken(w http.ResponseWriter, r *http.Request) {
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body models.RefreshRequest
+	if err = json.Unmarshal(request, &body); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if body.RefreshToken == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("o refresh token é obrigatório"))
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	//refresh tokens de aplicativos de terceiros só são trocados em /oauth/token
+	storedToken, err := consumeRefreshToken(db, body.RefreshToken, "")
+	if err != nil {
+		if errors.Is(err, errInvalidRefreshToken) {
+			response.Erro(w, http.StatusUnauthorized, err)
+			return
+		}
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	sessions := repositories.NewSessionRep(db)
+	session, err := sessions.SearchByFamily(storedToken.FamilyID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if session.ID == 0 || session.RevokedAt != nil {
+		response.Erro(w, http.StatusUnauthorized, errors.New("sessão encerrada, faça login novamente"))
+		return
+	}
+
+	if err = sessions.Touch(session.ID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	authData, err := issueCredentials(db, session)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, authData)
+}
+
+//startSession registra o login em um novo dispositivo e emite os primeiros tokens da sessão
+func startSession(db *sql.DB, r *http.Request, userID uint64) (models.AuthData, error) {
+	familyID, err := auth.NewOpaqueToken(16)
+	if err != nil {
+		return models.AuthData{}, err
+	}
+
+	userAgent := r.UserAgent()
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	session := models.Session{UserID: userID, FamilyID: familyID, UserAgent: userAgent, IP: clientIP(r)}
+	if session.ID, err = repositories.NewSessionRep(db).Create(session); err != nil {
+		return models.AuthData{}, err
+	}
+
+	return issueCredentials(db, session)
+}
+
+//issueCredentials gera o access token e um novo refresh token da sessão
+func issueCredentials(db *sql.DB, session models.Session) (models.AuthData, error) {
+	roles, err := repositories.NewRoleRep(db).SearchByUser(session.UserID)
+	if err != nil {
+		return models.AuthData{}, err
+	}
+
+	token, err := auth.CreateToken(session.UserID, session.ID, roles)
+	if err != nil {
+		return models.AuthData{}, err
+	}
+
+	refreshToken, err := issueRefreshToken(db, models.RefreshToken{UserID: session.UserID, FamilyID: session.FamilyID})
+	if err != nil {
+		return models.AuthData{}, err
+	}
+
+	return models.AuthData{
+		ID:           strconv.FormatUint(session.UserID, 10),
+		Token:        token,
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+//issueRefreshToken grava um novo refresh token para o usuário, a família, o cliente e os escopos de base.
+//Uma família vazia inicia uma nova cadeia de refresh tokens.
+func issueRefreshToken(db *sql.DB, base models.RefreshToken) (string, error) {
+	var err error
+	if base.FamilyID == "" {
+		if base.FamilyID, err = auth.NewOpaqueToken(16); err != nil {
+			return "", err
+		}
+	}
+
+	refreshToken, err := auth.NewOpaqueToken(32)
+	if err != nil {
+		return "", err
+	}
+
+	base.TokenHash = auth.HashToken(refreshToken)
+	base.ExpiresAt = time.Now().Add(config.RefreshTokenTTL)
+
+	if err = repositories.NewRefreshTokenRep(db).Create(base); err != nil {
+		return "", err
+	}
+
+	return refreshToken, nil
+}
+
+var (
+	//errInvalidRefreshToken agrupa os motivos pelos quais um refresh token é recusado
+	errInvalidRefreshToken = errors.New("refresh token inválido")
+	//errRefreshTokenReused indica um token já trocado: quem o apresenta pode tê-lo roubado, então a família é revogada
+	errRefreshTokenReused = fmt.Errorf("%w: token reutilizado, faça login novamente", errInvalidRefreshToken)
+)
+
+//consumeRefreshToken confere e consome um refresh token do cliente informado (vazio no login normal). Cada token
+//só pode ser usado uma vez, e a reutilização de um token já trocado revoga toda a família, derrubando quem quer
+//que esteja com ela.
+func consumeRefreshToken(db *sql.DB, token, clientID string) (models.RefreshToken, error) {
+	rep := repositories.NewRefreshTokenRep(db)
+	storedToken, err := rep.SearchByHash(auth.HashToken(token))
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	if err = checkRefreshToken(storedToken, clientID, time.Now()); err != nil {
+		if errors.Is(err, errRefreshTokenReused) {
+			if err := revokeFamily(db, storedToken.FamilyID); err != nil {
+				return models.RefreshToken{}, err
+			}
+		}
+
+		return models.RefreshToken{}, err
+	}
+
+	//se duas requisições chegarem juntas com o mesmo token apenas uma consegue consumi-lo
+	used, err := rep.MarkUsed(storedToken.ID)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+
+	if !used {
+		if err := revokeFamily(db, storedToken.FamilyID); err != nil {
+			return models.RefreshToken{}, err
+		}
+
+		return models.RefreshToken{}, errRefreshTokenReused
+	}
+
+	return storedToken, nil
+}
+
+//checkRefreshToken decide se o token guardado pode ser trocado agora pelo cliente informado
+func checkRefreshToken(storedToken models.RefreshToken, clientID string, now time.Time) error {
+	if storedToken.ID == 0 || storedToken.RevokedAt != nil {
+		return errInvalidRefreshToken
+	}
+
+	//o token de outro cliente é recusado antes de tudo, sem ser consumido e sem derrubar a família do dono
+	if storedToken.ClientID != clientID {
+		return errInvalidRefreshToken
+	}
+
+	if storedToken.UsedAt != nil {
+		return errRefreshTokenReused
+	}
+
+	if now.After(storedToken.ExpiresAt) {
+		return fmt.Errorf("%w: token expirado", errInvalidRefreshToken)
+	}
+
+	return nil
+}
+
+//revokeFamily revoga a família de refresh tokens e encerra a sessão dona dela
+func revokeFamily(db *sql.DB, familyID string) error {
+	if err := repositories.NewRefreshTokenRep(db).RevokeFamily(familyID); err != nil {
+		return err
+	}
+
+	return repositories.NewSessionRep(db).RevokeFamily(familyID)
+}
@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"api/src/models"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCheckRefreshToken(t *testing.T) {
+	now := time.Now()
+	past := now.Add(-time.Minute)
+
+	valid := models.RefreshToken{ID: 1, FamilyID: "familia", ExpiresAt: now.Add(time.Hour)}
+
+	used := valid
+	used.UsedAt = &past
+
+	revoked := valid
+	revoked.RevokedAt = &past
+
+	expired := valid
+	expired.ExpiresAt = past
+
+	oauth := valid
+	oauth.ClientID = "aplicativo"
+
+	usedByOAuth := oauth
+	usedByOAuth.UsedAt = &past
+
+	tests := []struct {
+		name     string
+		token    models.RefreshToken
+		clientID string
+		want     error
+	}{
+		{"válido", valid, "", nil},
+		{"válido do aplicativo", oauth, "aplicativo", nil},
+		{"inexistente", models.RefreshToken{}, "", errInvalidRefreshToken},
+		{"revogado", revoked, "", errInvalidRefreshToken},
+		{"expirado", expired, "", errInvalidRefreshToken},
+		{"reutilizado", used, "", errRefreshTokenReused},
+		{"reutilizado pelo aplicativo", usedByOAuth, "aplicativo", errRefreshTokenReused},
+		{"do aplicativo no login normal", oauth, "", errInvalidRefreshToken},
+		{"do login normal em um aplicativo", valid, "aplicativo", errInvalidRefreshToken},
+		{"de outro aplicativo", oauth, "outro", errInvalidRefreshToken},
+		//sem isso qualquer aplicativo com um token vazado derrubaria a família do dono
+		{"já usado por outro aplicativo", usedByOAuth, "outro", errInvalidRefreshToken},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			err := checkRefreshToken(test.token, test.clientID, now)
+			if test.want == nil {
+				if err != nil {
+					t.Errorf("checkRefreshToken() erro = %v, esperado nenhum", err)
+				}
+				return
+			}
+
+			if !errors.Is(err, test.want) {
+				t.Errorf("checkRefreshToken() erro = %v, esperado %v", err, test.want)
+			}
+
+			if test.want == errInvalidRefreshToken && errors.Is(err, errRefreshTokenReused) {
+				t.Errorf("checkRefreshToken() erro = %v, não deveria revogar a família", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"database/sql"
+	"encoding/json"
+	"io"
+	"net/http"
+)
+
+//Logout revoga o access token usado na requisição e encerra a sessão dele. Tokens sem sessão, emitidos antes
+//do controle de sessões, ainda podem mandar o refresh token no corpo para revogá-lo.
+func Logout(w http.ResponseWriter, r *http.Request) {
+	claims, err := auth.GetClaims(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body models.RefreshRequest
+	if len(request) > 0 {
+		if err = json.Unmarshal(request, &body); err != nil {
+			response.Erro(w, http.StatusBadRequest, err)
+			return
+		}
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewRevokedTokenRep(db)
+	if err = rep.Revoke(claims.TokenID, claims.UserID, claims.ExpiresAt); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if claims.SessionID != 0 {
+		session, err := repositories.NewSessionRep(db).GetByID(claims.SessionID)
+		if err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if session.ID != 0 && session.UserID == claims.UserID {
+			if err = revokeFamily(db, session.FamilyID); err != nil {
+				response.Erro(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+
+	if body.RefreshToken != "" {
+		refreshRep := repositories.NewRefreshTokenRep(db)
+		storedToken, err := refreshRep.SearchByHash(auth.HashToken(body.RefreshToken))
+		if err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		if storedToken.ID != 0 && storedToken.UserID == claims.UserID {
+			if err = revokeFamily(db, storedToken.FamilyID); err != nil {
+				response.Erro(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//LogoutAll encerra todas as sessões do usuário, em todos os dispositivos
+func LogoutAll(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if err = revokeAllTokens(db, userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//revokeAllTokens encerra todas as sessões e invalida todos os access tokens, refresh tokens e personal access tokens
+//já emitidos para o usuário
+func revokeAllTokens(db *sql.DB, userID uint64) error {
+	if err := repositories.NewUserRep(db).RevokeTokens(userID); err != nil {
+		return err
+	}
+
+	if err := repositories.NewSessionRep(db).RevokeAllFromUser(userID); err != nil {
+		return err
+	}
+
+	if err := repositories.NewRefreshTokenRep(db).RevokeAllFromUser(userID); err != nil {
+		return err
+	}
+
+	return repositories.NewPersonalAccessTokenRep(db).RevokeAllFromUser(userID)
+}
@@ -0,0 +1,154 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/config"
+	"api/src/db"
+	"api/src/mailer"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//SendMagicLink envia por email um link que faz login sem senha. Assim como em ForgotPassword, a resposta
+//é sempre a mesma, e no mesmo tempo, exista ou não uma conta com o email informado.
+func SendMagicLink(w http.ResponseWriter, r *http.Request) {
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body models.MagicLinkRequest
+	if err = json.Unmarshal(request, &body); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body.Email = strings.TrimSpace(body.Email)
+	if body.Email == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("o campo email é obrigatório"))
+		return
+	}
+
+	wait, err := reserveEmail("magic_link", body.Email, clientIP(r))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if wait > 0 {
+		tooManyAttempts(w, wait)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	user, err := repositories.NewUserRep(db).SearchByEmail(body.Email)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	//como em ForgotPassword, o link é gerado e enviado depois da resposta
+	if user.ID != 0 {
+		go sendMagicLink(user.ID, user.Email)
+	}
+
+	response.JSON(w, http.StatusAccepted, nil)
+}
+
+//sendMagicLink cria o link de login e o envia por email. Roda depois da resposta, então os erros só vão para o log
+func sendMagicLink(userID uint64, email string) {
+	token, err := auth.NewOpaqueToken(32)
+	if err != nil {
+		log.Printf("erro ao gerar o link de login: %v", err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		log.Printf("erro ao conectar ao banco para o link de login: %v", err)
+		return
+	}
+	defer db.Close()
+
+	if err = repositories.NewMagicLinkRep(db).Create(userID, email, auth.HashToken(token), time.Now().Add(config.MagicLinkTTL)); err != nil {
+		log.Printf("erro ao registrar o link de login: %v", err)
+		return
+	}
+
+	//o link abre o front-end, que chama GET /login/magic/{token} e guarda os tokens recebidos
+	link := fmt.Sprintf("%s/login/magic?token=%s", config.AppURL, url.QueryEscape(token))
+	if err = mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Seu link de acesso ao DevBook",
+		Body: fmt.Sprintf(
+			"Use o link abaixo para entrar no DevBook sem senha. Ele vale por %d minutos e só pode ser usado uma vez:\n%s\n\nSe não foi você, ignore este email.",
+			int(config.MagicLinkTTL.Minutes()), link,
+		),
+	}); err != nil {
+		log.Printf("erro ao enviar o link de login: %v", err)
+	}
+}
+
+//LoginMagicLink consome o link recebido por email e conclui o login como em Login, inclusive com o desafio do 2FA
+func LoginMagicLink(w http.ResponseWriter, r *http.Request) {
+	params := mux.Vars(r)
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	userID, email, err := repositories.NewMagicLinkRep(db).Consume(auth.HashToken(params["token"]))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if userID == 0 {
+		response.Erro(w, http.StatusUnauthorized, errors.New("link inválido ou expirado"))
+		return
+	}
+
+	rep := repositories.NewUserRep(db)
+	user, err := rep.GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if user.ID == 0 || user.Email != email {
+		response.Erro(w, http.StatusUnauthorized, errors.New("o email da conta mudou depois que este link foi enviado"))
+		return
+	}
+
+	//quem abriu o link provou que recebe os emails da conta
+	if user.EmailVerifiedAt == nil {
+		if _, err = rep.MarkEmailVerified(user.ID, email); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	finishLogin(w, r, db, user.ID)
+}
@@ -0,0 +1,504 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"crypto/subtle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//authorizationCodeTTL é a validade do código entregue na redirect_uri; o aplicativo deve trocá-lo logo em seguida
+const authorizationCodeTTL = 10 * time.Minute
+
+//CreateOAuthClient registra um aplicativo de terceiros. Aplicativos confidenciais (com back-end) recebem
+//um client_secret, que só aparece nesta resposta; aplicativos públicos dependem apenas do PKCE.
+func CreateOAuthClient(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var client models.OAuthClient
+	if err = json.Unmarshal(request, &client); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = client.Prepare(); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	client.OwnerID = userID
+	if client.ID, err = auth.NewOpaqueToken(16); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if client.Confidential {
+		if client.Secret, err = auth.NewOpaqueToken(32); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+		client.SecretHash = auth.HashToken(client.Secret)
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if err = repositories.NewOAuthClientRep(db).Create(client); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	client.CreatedAt = time.Now()
+	response.JSON(w, http.StatusCreated, client)
+}
+
+func GetOAuthClients(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	clients, err := repositories.NewOAuthClientRep(db).SearchByOwner(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, clients)
+}
+
+//DeleteOAuthClient apaga o aplicativo; os refresh tokens emitidos para ele deixam de existir junto
+func DeleteOAuthClient(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	deleted, err := repositories.NewOAuthClientRep(db).Delete(params["clientId"], userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !deleted {
+		response.Erro(w, http.StatusNotFound, errors.New("aplicativo não encontrado"))
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//GetOAuthGrants lista os aplicativos que o usuário autorizou a agir em nome dele
+func GetOAuthGrants(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	grants, err := repositories.NewRefreshTokenRep(db).SearchGrantsByUser(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, grants)
+}
+
+//DeleteOAuthGrant retira a autorização dada a um aplicativo. Os refresh tokens dele param de valer na hora;
+//os access tokens já emitidos, que não são consultados no banco, expiram em até OAuthTokenTTL.
+func DeleteOAuthGrant(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	revoked, err := repositories.NewRefreshTokenRep(db).RevokeClient(userID, params["clientId"])
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !revoked {
+		response.Erro(w, http.StatusNotFound, errors.New("autorização não encontrada"))
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//GetAuthorize valida o pedido de autorização e devolve os dados que o front-end mostra na tela de consentimento
+func GetAuthorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	authRequest := models.AuthorizationRequest{
+		ResponseType:        query.Get("response_type"),
+		ClientID:            query.Get("client_id"),
+		RedirectURI:         query.Get("redirect_uri"),
+		Scope:               query.Get("scope"),
+		State:               query.Get("state"),
+		CodeChallenge:       query.Get("code_challenge"),
+		CodeChallengeMethod: query.Get("code_challenge_method"),
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	client, scopes, err := validateAuthorizationRequest(db, authRequest)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.OAuthConsent{
+		Client:      models.OAuthClient{ID: client.ID, Name: client.Name, RedirectURIs: client.RedirectURIs, Confidential: client.Confidential},
+		Scopes:      scopes,
+		RedirectURI: authRequest.RedirectURI,
+		State:       authRequest.State,
+	})
+}
+
+//PostAuthorize registra a decisão do usuário na tela de consentimento e devolve para onde o front-end deve
+//redirecioná-lo: a redirect_uri do aplicativo com o código de autorização ou com o erro access_denied
+func PostAuthorize(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var authRequest models.AuthorizationRequest
+	if err = json.Unmarshal(request, &authRequest); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	client, scopes, err := validateAuthorizationRequest(db, authRequest)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	params := url.Values{}
+	if authRequest.State != "" {
+		params.Set("state", authRequest.State)
+	}
+
+	if !authRequest.Approve {
+		params.Set("error", "access_denied")
+		response.JSON(w, http.StatusOK, models.OAuthRedirect{RedirectTo: withQuery(authRequest.RedirectURI, params)})
+		return
+	}
+
+	code, err := auth.NewOpaqueToken(32)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = repositories.NewAuthorizationCodeRep(db).Create(models.AuthorizationCode{
+		UserID:              userID,
+		ClientID:            client.ID,
+		CodeHash:            auth.HashToken(code),
+		RedirectURI:         authRequest.RedirectURI,
+		Scopes:              scopes,
+		CodeChallenge:       authRequest.CodeChallenge,
+		CodeChallengeMethod: authRequest.CodeChallengeMethod,
+		ExpiresAt:           time.Now().Add(authorizationCodeTTL),
+	}); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	params.Set("code", code)
+	response.JSON(w, http.StatusOK, models.OAuthRedirect{RedirectTo: withQuery(authRequest.RedirectURI, params)})
+}
+
+//validateAuthorizationRequest confere o cliente, a redirect_uri, os escopos e o PKCE do pedido de autorização.
+//Como a redirect_uri pode não ser confiável, os erros são devolvidos ao front-end em vez de redirecionados.
+func validateAuthorizationRequest(db *sql.DB, authRequest models.AuthorizationRequest) (models.OAuthClient, []string, error) {
+	if authRequest.ResponseType != "code" {
+		return models.OAuthClient{}, nil, errors.New("response_type não suportado, use code")
+	}
+
+	client, err := repositories.NewOAuthClientRep(db).GetByID(authRequest.ClientID)
+	if err != nil {
+		return models.OAuthClient{}, nil, err
+	}
+
+	if client.ID == "" {
+		return models.OAuthClient{}, nil, errors.New("aplicativo não encontrado")
+	}
+
+	if !client.HasRedirectURI(authRequest.RedirectURI) {
+		return models.OAuthClient{}, nil, errors.New("redirect_uri não cadastrada para este aplicativo")
+	}
+
+	scopes := strings.Fields(authRequest.Scope)
+	if err = models.ValidateScopes(scopes); err != nil {
+		return models.OAuthClient{}, nil, err
+	}
+
+	if authRequest.CodeChallengeMethod != "S256" || authRequest.CodeChallenge == "" {
+		return models.OAuthClient{}, nil, errors.New("o PKCE é obrigatório, informe code_challenge com code_challenge_method S256")
+	}
+
+	return client, scopes, nil
+}
+
+func withQuery(redirectURI string, params url.Values) string {
+	separator := "?"
+	if strings.Contains(redirectURI, "?") {
+		separator = "&"
+	}
+
+	return redirectURI + separator + params.Encode()
+}
+
+//Token é o token endpoint do OAuth: troca um código de autorização ou um refresh token de um aplicativo por
+//novos tokens. Segue o RFC 6749, então recebe o corpo como formulário e responde erros no formato OAuth.
+func Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		oauthError(w, http.StatusBadRequest, "invalid_request", err.Error())
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	client, err := authenticateClient(db, r)
+	if err != nil {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+		oauthError(w, http.StatusUnauthorized, "invalid_client", err.Error())
+		return
+	}
+
+	switch r.PostForm.Get("grant_type") {
+	case "authorization_code":
+		exchangeAuthorizationCode(w, r, db, client)
+	case "refresh_token":
+		exchangeRefreshToken(w, r, db, client)
+	default:
+		oauthError(w, http.StatusBadRequest, "unsupported_grant_type", "use authorization_code ou refresh_token")
+	}
+}
+
+//authenticateClient identifica o aplicativo pelo HTTP Basic ou pelos campos do formulário.
+//Aplicativos públicos só informam o client_id.
+func authenticateClient(db *sql.DB, r *http.Request) (models.OAuthClient, error) {
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+
+	client, err := repositories.NewOAuthClientRep(db).GetByID(clientID)
+	if err != nil {
+		return models.OAuthClient{}, err
+	}
+
+	if client.ID == "" {
+		return models.OAuthClient{}, errors.New("aplicativo não encontrado")
+	}
+
+	if client.Confidential && subtle.ConstantTimeCompare([]byte(auth.HashToken(secret)), []byte(client.SecretHash)) != 1 {
+		return models.OAuthClient{}, errors.New("client_secret inválido")
+	}
+
+	return client, nil
+}
+
+func exchangeAuthorizationCode(w http.ResponseWriter, r *http.Request, db *sql.DB, client models.OAuthClient) {
+	rep := repositories.NewAuthorizationCodeRep(db)
+	code, err := rep.SearchByHash(auth.HashToken(r.PostForm.Get("code")))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if code.ID == 0 || code.ClientID != client.ID {
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "código de autorização inválido")
+		return
+	}
+
+	//um código reutilizado provavelmente vazou, então os tokens emitidos com ele também são revogados
+	if code.UsedAt != nil {
+		if code.FamilyID != "" {
+			if err = revokeFamily(db, code.FamilyID); err != nil {
+				response.Erro(w, http.StatusInternalServerError, err)
+				return
+			}
+		}
+
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "código de autorização já utilizado")
+		return
+	}
+
+	if code.RedirectURI != r.PostForm.Get("redirect_uri") {
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "redirect_uri diferente da usada na autorização")
+		return
+	}
+
+	if !auth.VerifyCodeChallenge(r.PostForm.Get("code_verifier"), code.CodeChallenge, code.CodeChallengeMethod) {
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "code_verifier inválido")
+		return
+	}
+
+	used, err := rep.MarkUsed(code.ID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !used {
+		oauthError(w, http.StatusBadRequest, "invalid_grant", "código de autorização expirado ou já utilizado")
+		return
+	}
+
+	familyID, err := auth.NewOpaqueToken(16)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = rep.SetFamily(code.ID, familyID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	issueOAuthToken(w, db, models.RefreshToken{UserID: code.UserID, FamilyID: familyID, ClientID: client.ID, Scopes: code.Scopes})
+}
+
+//exchangeRefreshToken renova os tokens de um aplicativo. O escopo pode ser reduzido, mas nunca ampliado.
+func exchangeRefreshToken(w http.ResponseWriter, r *http.Request, db *sql.DB, client models.OAuthClient) {
+	storedToken, err := consumeRefreshToken(db, r.PostForm.Get("refresh_token"), client.ID)
+	if err != nil {
+		if errors.Is(err, errInvalidRefreshToken) {
+			oauthError(w, http.StatusBadRequest, "invalid_grant", err.Error())
+			return
+		}
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	scopes := storedToken.Scopes
+	if requested := strings.Fields(r.PostForm.Get("scope")); len(requested) > 0 {
+		claims := auth.Claims{Scoped: true, Scopes: storedToken.Scopes}
+		if !claims.HasScopes(requested...) {
+			oauthError(w, http.StatusBadRequest, "invalid_scope", "o escopo pedido não foi autorizado pelo usuário")
+			return
+		}
+		scopes = requested
+	}
+
+	issueOAuthToken(w, db, models.RefreshToken{UserID: storedToken.UserID, FamilyID: storedToken.FamilyID, ClientID: client.ID, Scopes: scopes})
+}
+
+//issueOAuthToken gera o access token com escopo e o refresh token do aplicativo, na família informada
+func issueOAuthToken(w http.ResponseWriter, db *sql.DB, base models.RefreshToken) {
+	accessToken, err := auth.CreateScopedToken(base.UserID, base.ClientID, base.Scopes)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	refreshToken, err := issueRefreshToken(db, base)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, http.StatusOK, models.OAuthToken{
+		AccessToken:  accessToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int(auth.OAuthTokenTTL.Seconds()),
+		RefreshToken: refreshToken,
+		Scope:        strings.Join(base.Scopes, " "),
+	})
+}
+
+func oauthError(w http.ResponseWriter, statusCode int, code, description string) {
+	w.Header().Set("Cache-Control", "no-store")
+	response.JSON(w, statusCode, models.OAuthError{Error: code, Description: description})
+}
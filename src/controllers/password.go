@@ -0,0 +1,201 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/config"
+	"api/src/db"
+	"api/src/mailer"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"api/src/security"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//ForgotPassword envia por email um link para redefinir a senha. A resposta é sempre a mesma, e no mesmo tempo,
+//exista ou não uma conta com o email informado, para não revelar quem está cadastrado.
+func ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body models.ForgotPassword
+	if err = json.Unmarshal(request, &body); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body.Email = strings.TrimSpace(body.Email)
+	if body.Email == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("o campo email é obrigatório"))
+		return
+	}
+
+	wait, err := reserveEmail("password_reset", body.Email, clientIP(r))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if wait > 0 {
+		tooManyAttempts(w, wait)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	user, err := repositories.NewUserRep(db).SearchByEmail(body.Email)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	//o token e o email ficam para depois da resposta; senão o tempo dela revelaria quem tem conta
+	if user.ID != 0 {
+		go sendPasswordReset(user.ID, body.Email)
+	}
+
+	response.JSON(w, http.StatusAccepted, nil)
+}
+
+//sendPasswordReset cria o token de redefinição e o envia por email. Roda depois da resposta, então os erros só vão para o log
+func sendPasswordReset(userID uint64, email string) {
+	token, err := auth.NewOpaqueToken(32)
+	if err != nil {
+		log.Printf("erro ao gerar o token de redefinição de senha: %v", err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		log.Printf("erro ao conectar ao banco para a redefinição de senha: %v", err)
+		return
+	}
+	defer db.Close()
+
+	if err = repositories.NewPasswordResetRep(db).Create(userID, auth.HashToken(token), time.Now().Add(config.PasswordResetTTL)); err != nil {
+		log.Printf("erro ao registrar o token de redefinição de senha: %v", err)
+		return
+	}
+
+	link := fmt.Sprintf("%s/password/reset?token=%s", config.AppURL, url.QueryEscape(token))
+	if err = mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Redefinição de senha do DevBook",
+		Body: fmt.Sprintf(
+			"Recebemos um pedido para redefinir a sua senha.\n\nUse o link abaixo em até %d minutos:\n%s\n\nSe não foi você, ignore este email.",
+			int(config.PasswordResetTTL.Minutes()), link,
+		),
+	}); err != nil {
+		log.Printf("erro ao enviar o email de redefinição de senha: %v", err)
+	}
+}
+
+//ResetPassword consome o token recebido por email e troca a senha, derrubando todas as sessões abertas
+func ResetPassword(w http.ResponseWriter, r *http.Request) {
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body models.ResetPassword
+	if err = json.Unmarshal(request, &body); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if body.Token == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("o token é obrigatório"))
+		return
+	}
+
+	if body.NewPassword == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("o campo senha é obrigatório"))
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	resets := repositories.NewPasswordResetRep(db)
+	tokenHash := auth.HashToken(body.Token)
+
+	//o dono do token é buscado sem consumi-lo, para que uma senha recusada pela política não gaste o link
+	userID, err := resets.SearchValidByHash(tokenHash)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if userID == 0 {
+		response.Erro(w, http.StatusBadRequest, errors.New("token inválido ou expirado"))
+		return
+	}
+
+	userRep := repositories.NewUserRep(db)
+	user, err := userRep.GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if user.ID == 0 {
+		response.Erro(w, http.StatusBadRequest, errors.New("token inválido ou expirado"))
+		return
+	}
+
+	if err = security.CheckPassword("new_password", body.NewPassword, user.Nick, user.Email); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	newHashedPassword, err := security.Hash(body.NewPassword)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	//outra requisição pode ter usado o mesmo token enquanto a senha era conferida
+	consumedBy, err := resets.Consume(tokenHash)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if consumedBy != userID {
+		response.Erro(w, http.StatusBadRequest, errors.New("token inválido ou expirado"))
+		return
+	}
+
+	if err = userRep.UpdatePassword(userID, string(newHashedPassword)); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = revokeAllTokens(db, userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
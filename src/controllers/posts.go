@@ -6,6 +6,7 @@ import (
 	"api/src/models"
 	"api/src/repositories"
 	"api/src/response"
+	"api/src/storage"
 	"encoding/json"
 	"errors"
 	"io"
@@ -35,6 +36,7 @@ func NewPost(w http.ResponseWriter, r*http.Request){
 	}
 
 	post.AuthorID = userID
+	post.Kind = models.PostKindPost
 
 	if err = post.Prepare(); err != nil {
 		response.Erro(w, http.StatusBadRequest, err)
@@ -48,6 +50,10 @@ func NewPost(w http.ResponseWriter, r*http.Request){
 	}
 	defer db.Close()
 
+	if !canPost(w, db, userID) {
+		return
+	}
+
 	rep := repositories.NewPostRep(db)
 	post.ID, err = rep.CreatePost(post)
 	if err != nil {
@@ -55,6 +61,14 @@ func NewPost(w http.ResponseWriter, r*http.Request){
 		return
 	}
 
+	//relê a publicação para devolver as menções já resolvidas
+	post, err = rep.GetOnePost(post.ID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	post.SetURLs(storage.URL)
 	response.JSON(w, http.StatusCreated, post)
 }
 
@@ -79,10 +93,17 @@ func GetPosts(w http.ResponseWriter, r*http.Request){
 		return
 	}
 
+	setPostURLs(posts)
 	response.JSON(w, http.StatusOK, posts)
 }
 
 func GetOnePost(w http.ResponseWriter, r*http.Request){
+	viewerID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 	postID, err := strconv.ParseUint(params["idPost"], 10, 64)
 	if err != nil {
@@ -98,17 +119,18 @@ func GetOnePost(w http.ResponseWriter, r*http.Request){
 	defer db.Close()
 
 	rep := repositories.NewPostRep(db)
-	post, err := rep.GetOnePost(postID)
+	post, err := rep.GetOnePost(postID, viewerID)
 	if err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	post.SetURLs(storage.URL)
 	response.JSON(w, http.StatusOK, post)
 }
 
 func UpdatePost(w http.ResponseWriter, r*http.Request){
-	userID, err := auth.GetUserID(r)
+	claims, err := auth.GetClaims(r)
 	if err != nil {
 		response.Erro(w, http.StatusUnprocessableEntity, err)
 		return
@@ -128,36 +150,74 @@ func UpdatePost(w http.ResponseWriter, r*http.Request){
 	}
 	defer db.Close()
 
+	//editar pode publicar um rascunho e avisar novos mencionados, então vale a mesma regra de quem publica
+	if !canPost(w, db, claims.UserID) {
+		return
+	}
+
 	rep := repositories.NewPostRep(db)
-	postFromDB, err := rep.GetOnePost(postID)
+	postFromDB, err := rep.GetOnePost(postID, claims.UserID)
 	if err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	if postFromDB.AuthorID != userID {
-		response.Erro(w, http.StatusForbidden, errors.New("não é possivel atualizar uma publicação que não seja sua"))
+	if postFromDB.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
 		return
 	}
 
+	if postFromDB.AuthorID != claims.UserID {
+		if !claims.HasAnyRole(models.RoleAdmin, models.RoleModerator) {
+			response.Erro(w, http.StatusForbidden, errors.New("não é possivel atualizar uma publicação que não seja sua"))
+			return
+		}
+	}
+
 	request, err := io.ReadAll(r.Body)
 	if err != nil {
 		response.Erro(w, http.StatusUnprocessableEntity, err)
 		return
 	}
 
+	if postFromDB.Kind == models.PostKindRepost {
+		response.Erro(w, http.StatusBadRequest, errors.New("um repost não pode ser editado"))
+		return
+	}
+
 	var post models.Post
 	if err := json.Unmarshal(request, &post); err != nil {
 		response.Erro(w, http.StatusBadRequest, err)
 		return
 	}
 
+	//sem situação informada a publicação continua como está, inclusive o agendamento
+	if post.Status == "" {
+		post.Status = postFromDB.Status
+		if post.PublishAt == nil {
+			post.PublishAt = postFromDB.PublishAt
+		}
+	}
+
+	if postFromDB.Status == models.PostStatusPublished && post.Status != models.PostStatusPublished {
+		response.Erro(w, http.StatusBadRequest, errors.New("uma publicação já publicada não pode voltar a ser rascunho nem ser agendada"))
+		return
+	}
+
+	post.Kind = postFromDB.Kind
 	if err := post.Prepare(); err != nil {
 		response.Erro(w, http.StatusBadRequest, err)
 		return
 	}
 
-	if err := rep.Update(postID, post); err != nil {
+	if postFromDB.AuthorID != claims.UserID {
+		if err := audit(db, claims.UserID, "update_post", "post", postID); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err := rep.Update(postID, postFromDB.AuthorID, claims.UserID, post); err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -166,7 +226,7 @@ func UpdatePost(w http.ResponseWriter, r*http.Request){
 }
 
 func DeletePost(w http.ResponseWriter, r*http.Request){
-	userID, err := auth.GetUserID(r)
+	claims, err := auth.GetClaims(r)
 	if err != nil {
 		response.Erro(w, http.StatusUnprocessableEntity, err)
 		return
@@ -187,18 +247,30 @@ func DeletePost(w http.ResponseWriter, r*http.Request){
 	defer db.Close()
 
 	rep := repositories.NewPostRep(db)
-	postFromDB, err := rep.GetOnePost(postID)
+	postFromDB, err := rep.GetOnePost(postID, claims.UserID)
 	if err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
 
-	if postFromDB.AuthorID != userID {
-		response.Erro(w, http.StatusForbidden, errors.New("não é possivel deletar uma publicação que não seja sua"))
+	if postFromDB.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
 		return
 	}
 
-	if err := rep.DeletePost(postID); err != nil {
+	if postFromDB.AuthorID != claims.UserID {
+		if !claims.HasAnyRole(models.RoleAdmin, models.RoleModerator) {
+			response.Erro(w, http.StatusForbidden, errors.New("não é possivel deletar uma publicação que não seja sua"))
+			return
+		}
+
+		if err := audit(db, claims.UserID, "delete_post", "post", postID); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	if err := rep.DeletePost(postID, claims.UserID); err != nil {
 	response.Erro(w, http.StatusInternalServerError, err)
 	return
 	}
@@ -207,6 +279,12 @@ func DeletePost(w http.ResponseWriter, r*http.Request){
 }
 
 func GetPostsByUser(w http.ResponseWriter, r *http.Request){
+	viewerID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 	userID, err := strconv.ParseUint(params["userID"], 10, 64)
 	if err != nil {
@@ -222,16 +300,62 @@ func GetPostsByUser(w http.ResponseWriter, r *http.Request){
 	defer db.Close()
 
 	rep := repositories.NewPostRep(db)
-	posts, err := rep.GetUserPosts(userID)
+	posts, err := rep.GetUserPosts(userID, viewerID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	setPostURLs(posts)
+	response.JSON(w, http.StatusOK, posts)
+}
+
+//GetUserMentions lista as publicações que mencionam o usuário, página a página
+func GetUserMentions(w http.ResponseWriter, r *http.Request){
+	viewerID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	limit, offset, err := pagination(r)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	posts, err := repositories.NewPostRep(db).SearchMentions(userID, viewerID, limit, offset)
 	if err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	setPostURLs(posts)
 	response.JSON(w, http.StatusOK, posts)
 }
 
+//LikePost curte a publicação em nome do usuário logado; curtir de novo não conta outra curtida
 func LikePost(w http.ResponseWriter, r *http.Request){
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 	postID, err := strconv.ParseUint(params["postID"], 10, 64)
 	if err != nil {
@@ -247,7 +371,11 @@ func LikePost(w http.ResponseWriter, r *http.Request){
 	defer db.Close()
 
 	rep := repositories.NewPostRep(db)
-	if err := rep.Like(postID); err != nil {
+	if !postExists(w, rep, postID, userID) {
+		return
+	}
+
+	if _, err := rep.Like(postID, userID); err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
@@ -255,7 +383,14 @@ func LikePost(w http.ResponseWriter, r *http.Request){
 	response.JSON(w, http.StatusOK, nil)
 }
 
+//UnlikePost remove a curtida do usuário logado, se houver uma
 func UnlikePost(w http.ResponseWriter, r *http.Request){
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
 	params := mux.Vars(r)
 	postID, err := strconv.ParseUint(params["postID"], 10, 64)
 	if err != nil {
@@ -271,10 +406,62 @@ func UnlikePost(w http.ResponseWriter, r *http.Request){
 	defer db.Close()
 
 	rep := repositories.NewPostRep(db)
-	if err := rep.Unlike(postID); err != nil {
+	if _, err := rep.Unlike(postID, userID); err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
 
 	response.JSON(w, http.StatusOK, nil)
-}
\ No newline at end of file
+}
+
+//GetPostLikes lista os usuários que curtiram a publicação
+func GetPostLikes(w http.ResponseWriter, r *http.Request){
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["postID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewPostRep(db)
+	if !postExists(w, rep, postID, userID) {
+		return
+	}
+
+	users, err := rep.GetLikes(postID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, users)
+}
+
+//postExists responde 404 quando a publicação não existe; retorna false se a resposta já foi escrita
+func postExists(w http.ResponseWriter, rep *repositories.Posts, postID, viewerID uint64) bool {
+	post, err := rep.GetOnePost(postID, viewerID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	if post.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/config"
+	"api/src/db"
+	"api/src/media"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"api/src/storage"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//profileImage descreve uma imagem de perfil: o nome usado nas chaves, a proporção do recorte, as larguras geradas
+//e como a chave nova é gravada no usuário
+type profileImage struct {
+	name        string
+	ratioWidth  int
+	ratioHeight int
+	sizes       []int
+	save        func(rep *repositories.Users, userID uint64, key string) (string, error)
+}
+
+var (
+	avatarImage = profileImage{"avatar", 1, 1, models.AvatarSizes, (*repositories.Users).SetAvatar}
+	bannerImage = profileImage{"banner", 3, 1, models.BannerSizes, (*repositories.Users).SetBanner}
+)
+
+//UpdateAvatar troca o avatar do usuário pela imagem enviada em multipart/form-data no campo file
+func UpdateAvatar(w http.ResponseWriter, r *http.Request) {
+	updateProfileImage(w, r, avatarImage)
+}
+
+//UpdateBanner troca o banner do perfil pela imagem enviada em multipart/form-data no campo file
+func UpdateBanner(w http.ResponseWriter, r *http.Request) {
+	updateProfileImage(w, r, bannerImage)
+}
+
+func updateProfileImage(w http.ResponseWriter, r *http.Request, kind profileImage) {
+	claims, err := auth.GetClaims(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if userID != claims.UserID && !claims.HasAnyRole(models.RoleAdmin, models.RoleModerator) {
+		response.Erro(w, http.StatusForbidden, errors.New("você só pode alterar as imagens do seu usuário"))
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, config.MediaMaxBytes+1<<20)
+	file, _, err := r.FormFile("file")
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, errors.New("envie a imagem em multipart/form-data no campo file"))
+		return
+	}
+	defer file.Close()
+
+	data, err := io.ReadAll(io.LimitReader(file, config.MediaMaxBytes+1))
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if int64(len(data)) > config.MediaMaxBytes {
+		response.Erro(w, http.StatusRequestEntityTooLarge, fmt.Errorf("a imagem pode ter no máximo %d bytes", config.MediaMaxBytes))
+		return
+	}
+
+	variants, err := media.Variants(data, kind.ratioWidth, kind.ratioHeight, kind.sizes)
+	if errors.Is(err, media.ErrUnsupported) {
+		response.Erro(w, http.StatusUnsupportedMediaType, err)
+		return
+	}
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !canPost(w, db, claims.UserID) {
+		return
+	}
+
+	if !canOverride(w, db, claims, userID) {
+		return
+	}
+
+	rep := repositories.NewUserRep(db)
+	user, err := rep.GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if user.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+		return
+	}
+
+	if userID != claims.UserID {
+		if err := audit(db, claims.UserID, "update_"+kind.name, "user", userID); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	name := make([]byte, 16)
+	if _, err = rand.Read(name); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	key := fmt.Sprintf("users/%d/%s-%s.%s", userID, kind.name, hex.EncodeToString(name), variants[0].Extension)
+	for i, variant := range variants {
+		variantKey := models.VariantKey(key, kind.sizes[i])
+		if err = storage.Default.Put(variantKey, bytes.NewReader(variant.Data), int64(len(variant.Data)), variant.ContentType); err != nil {
+			deleteImageFiles(models.VariantKeys(key, kind.sizes[:i]))
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	previous, err := kind.save(rep, userID, key)
+	if err != nil {
+		deleteImageFiles(models.VariantKeys(key, kind.sizes))
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if previous != "" {
+		deleteImageFiles(models.VariantKeys(previous, kind.sizes))
+	}
+
+	user, err = rep.GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	user.SetURLs(storage.URL)
+	response.JSON(w, http.StatusOK, user)
+}
+
+//setUserURLs monta os endereços dos avatares e banners dos usuários; os repositórios devolvem só as chaves do armazenamento
+func setUserURLs(users []models.User) {
+	for i := range users {
+		users[i].SetURLs(storage.URL)
+	}
+}
+
+//deleteImageFiles apaga as versões de uma imagem; um arquivo que não sair do armazenamento só fica órfão
+func deleteImageFiles(keys []string) {
+	for _, key := range keys {
+		if err := storage.Default.Delete(key); err != nil {
+			log.Printf("erro ao apagar o arquivo %s: %v", key, err)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"api/src/storage"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//Repost compartilha a publicação com os seguidores do usuário. Repostar de novo não cria outro repost.
+func Repost(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["postID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !canPost(w, db, userID) {
+		return
+	}
+
+	rep := repositories.NewPostRep(db)
+	originalID, ok := repostTarget(w, rep, postID, userID)
+	if !ok {
+		return
+	}
+
+	//o insert já ignora o repost repetido, então duas requisições ao mesmo tempo não criam dois reposts
+	repostID, err := rep.CreateRepost(userID, originalID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if repostID == 0 {
+		repost, err := rep.SearchRepost(originalID, userID)
+		if err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		//o insert também é ignorado se a publicação original sumiu nesse meio tempo
+		if repost.ID == 0 {
+			response.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+			return
+		}
+
+		repost.SetURLs(storage.URL)
+		response.JSON(w, http.StatusOK, repost)
+		return
+	}
+
+	repost, err := rep.GetOnePost(repostID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	repost.SetURLs(storage.URL)
+	response.JSON(w, http.StatusCreated, repost)
+}
+
+//UndoRepost apaga o repost simples que o usuário fez da publicação, se houver um
+func UndoRepost(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["postID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewPostRep(db)
+	originalID, ok := repostTarget(w, rep, postID, userID)
+	if !ok {
+		return
+	}
+
+	repost, err := rep.SearchRepost(originalID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	//desfazer um repost não passa pela lixeira, já que não há o que restaurar
+	if repost.ID != 0 {
+		if err = rep.DeleteRepost(repost.ID); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	response.JSON(w, http.StatusOK, nil)
+}
+
+//QuotePost compartilha a publicação junto com um texto do usuário. Se a original for apagada,
+//a citação continua existindo e passa a mostrar que a publicação está indisponível.
+func QuotePost(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["postID"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var quote models.Post
+	if err = json.Unmarshal(request, &quote); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	quote.AuthorID = userID
+	quote.Kind = models.PostKindQuote
+
+	if err = quote.Prepare(); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !canPost(w, db, userID) {
+		return
+	}
+
+	rep := repositories.NewPostRep(db)
+	originalID, ok := repostTarget(w, rep, postID, userID)
+	if !ok {
+		return
+	}
+
+	quote.RepostOf = originalID
+
+	quote.ID, err = rep.CreatePost(quote)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	quote, err = rep.GetOnePost(quote.ID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	quote.SetURLs(storage.URL)
+	response.JSON(w, http.StatusCreated, quote)
+}
+
+//repostTarget retorna a publicação que deve ser compartilhada: repostar um repost compartilha a original dele.
+//Retorna false se a resposta de erro já foi escrita.
+func repostTarget(w http.ResponseWriter, rep *repositories.Posts, postID, userID uint64) (uint64, bool) {
+	post, err := rep.GetOnePost(postID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return 0, false
+	}
+
+	if post.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return 0, false
+	}
+
+	if post.Status != models.PostStatusPublished {
+		response.Erro(w, http.StatusBadRequest, errors.New("só é possível compartilhar uma publicação que já foi publicada"))
+		return 0, false
+	}
+
+	if post.Kind == models.PostKindRepost {
+		return post.RepostOf, true
+	}
+
+	return post.ID, true
+}
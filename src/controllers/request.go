@@ -0,0 +1,146 @@
+package controllers
+
+import (
+	"api/src/config"
+	"api/src/response"
+	"api/src/throttle"
+	"errors"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//clientIP retorna o IP de quem fez a requisição, considerando o X-Forwarded-For só quando a API está atrás de um proxy confiável
+func clientIP(r *http.Request) string {
+	if config.TrustProxy {
+		if forwarded := r.Header.Get("X-Forwarded-For"); forwarded != "" {
+			return strings.TrimSpace(strings.Split(forwarded, ",")[0])
+		}
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+//reserveLogin reserva uma tentativa de login para a conta e para o IP antes de conferir a senha,
+//retornando quanto tempo ainda falta esperar quando algum dos dois está bloqueado
+func reserveLogin(account, address string) (time.Duration, error) {
+	wait, err := throttle.Accounts.Reserve(account)
+	if err != nil || wait > 0 {
+		return wait, err
+	}
+
+	wait, err = throttle.Addresses.Reserve(address)
+	if err != nil {
+		return 0, err
+	}
+
+	//com o IP bloqueado a senha nem é conferida, então a tentativa não conta para a conta
+	if wait > 0 {
+		if err = throttle.Accounts.Release(account); err != nil {
+			return 0, err
+		}
+	}
+
+	return wait, nil
+}
+
+//loginSucceeded apaga as falhas da conta e devolve ao IP a tentativa reservada
+func loginSucceeded(account, address string) error {
+	if err := throttle.Accounts.Reset(account); err != nil {
+		return err
+	}
+
+	return throttle.Addresses.Release(address)
+}
+
+//reserveEmail conta um pedido de email (kind) para o destinatário e para o IP. Todo pedido conta, já que não há
+//como saber se ele foi legítimo; retorna quanto falta esperar quando algum dos dois está bloqueado
+func reserveEmail(kind, email, address string) (time.Duration, error) {
+	account := kind + ":" + strings.ToLower(email)
+	wait, err := throttle.Emails.Reserve(account)
+	if err != nil || wait > 0 {
+		return wait, err
+	}
+
+	wait, err = throttle.Addresses.Reserve(kind + ":" + address)
+	if err != nil {
+		return 0, err
+	}
+
+	if wait > 0 {
+		if err = throttle.Emails.Release(account); err != nil {
+			return 0, err
+		}
+	}
+
+	return wait, nil
+}
+
+func tooManyAttempts(w http.ResponseWriter, wait time.Duration) {
+	seconds := int(math.Ceil(wait.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	response.Erro(w, http.StatusTooManyRequests, fmt.Errorf("muitas tentativas, tente novamente em %d segundos", seconds))
+}
+
+//pagination lê os parâmetros page (a partir de 1) e limit da query, retornando o limit e o offset da consulta
+func pagination(r *http.Request) (int, int, error) {
+	page := 1
+
+	if value := r.URL.Query().Get("page"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			return 0, 0, fmt.Errorf("página inválida %s", value)
+		}
+		page = parsed
+	}
+
+	limit, err := pageLimit(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return limit, (page - 1) * limit, nil
+}
+
+//cursorPagination lê os parâmetros cursor e limit da query; sem cursor a busca começa pela primeira página
+func cursorPagination(r *http.Request) (uint64, int, error) {
+	var cursor uint64
+
+	if value := r.URL.Query().Get("cursor"); value != "" {
+		parsed, err := strconv.ParseUint(value, 10, 64)
+		if err != nil || parsed == 0 {
+			return 0, 0, fmt.Errorf("cursor inválido %s", value)
+		}
+		cursor = parsed
+	}
+
+	limit, err := pageLimit(r)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return cursor, limit, nil
+}
+
+func pageLimit(r *http.Request) (int, error) {
+	limit := 20
+
+	if value := r.URL.Query().Get("limit"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 || parsed > 100 {
+			return 0, errors.New("o limite deve ficar entre 1 e 100")
+		}
+		limit = parsed
+	}
+
+	return limit, nil
+}
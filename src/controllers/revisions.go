@@ -0,0 +1,135 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/diff"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+//GetPostRevisions lista as versões anteriores da publicação, da mais antiga para a mais nova
+func GetPostRevisions(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["idPost"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if !postExists(w, repositories.NewPostRep(db), postID, userID) {
+		return
+	}
+
+	revisions, err := repositories.NewRevisionRep(db).Search(postID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if revisions == nil {
+		revisions = []models.PostRevision{}
+	}
+
+	response.JSON(w, http.StatusOK, revisions)
+}
+
+//GetRevisionDiff compara duas versões da publicação, informadas em from e to, no formato de diff unificado.
+//A versão atual é a de número revision_count + 1.
+func GetRevisionDiff(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["idPost"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	from, err := strconv.ParseUint(r.URL.Query().Get("from"), 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, errors.New("informe a revisão inicial em from"))
+		return
+	}
+
+	to, err := strconv.ParseUint(r.URL.Query().Get("to"), 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, errors.New("informe a revisão final em to"))
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	post, err := repositories.NewPostRep(db).GetOnePost(postID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if post.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada"))
+		return
+	}
+
+	rep := repositories.NewRevisionRep(db)
+	versions := make([]models.PostRevision, 0, 2)
+	for _, number := range []uint64{from, to} {
+		if number == 0 || number > post.RevisionCount+1 {
+			response.Erro(w, http.StatusNotFound, fmt.Errorf("revisão %d não encontrada", number))
+			return
+		}
+
+		if number == post.RevisionCount+1 {
+			versions = append(versions, models.PostRevision{Revision: number, Title: post.Title, Content: post.Content})
+			continue
+		}
+
+		revision, err := rep.GetByNumber(postID, number)
+		if err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+
+		versions = append(versions, revision)
+	}
+
+	response.JSON(w, http.StatusOK, models.RevisionDiff{
+		From: from,
+		To:   to,
+		Diff: diff.Unified(
+			fmt.Sprintf("revisão %d", from),
+			fmt.Sprintf("revisão %d", to),
+			versions[0].Text(),
+			versions[1].Text(),
+		),
+	})
+}
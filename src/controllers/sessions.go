@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/config"
+	"api/src/db"
+	"api/src/repositories"
+	"api/src/response"
+	"errors"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//GetSessions lista os dispositivos em que o usuário está logado, marcando o da própria requisição
+func GetSessions(w http.ResponseWriter, r *http.Request) {
+	claims, err := auth.GetClaims(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	//sessões paradas por mais tempo que a validade do refresh token já não podem ser renovadas
+	sessions, err := repositories.NewSessionRep(db).SearchActiveByUser(claims.UserID, time.Now().Add(-config.RefreshTokenTTL))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == claims.SessionID
+	}
+
+	response.JSON(w, http.StatusOK, sessions)
+}
+
+//DeleteSession encerra a sessão de um dispositivo; os access tokens dela deixam de ser aceitos na hora
+func DeleteSession(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	sessionID, err := strconv.ParseUint(params["sessionId"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewSessionRep(db)
+	session, err := rep.GetByID(sessionID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	revoked, err := rep.Revoke(sessionID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !revoked {
+		response.Erro(w, http.StatusNotFound, errors.New("sessão não encontrada"))
+		return
+	}
+
+	if err = repositories.NewRefreshTokenRep(db).RevokeFamily(session.FamilyID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,127 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//CreatePersonalAccessToken cria um token para scripts e bots. O token completo só aparece nesta resposta;
+//depois disso o usuário vê apenas o prefixo e o hash.
+func CreatePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var token models.PersonalAccessToken
+	if err = json.Unmarshal(request, &token); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if err = token.Prepare(); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	token.UserID = userID
+	token.Token, token.Prefix, err = auth.NewPersonalAccessToken()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	token.TokenHash = auth.HashToken(token.Token)
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewPersonalAccessTokenRep(db)
+	token.ID, err = rep.Create(token)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	token.CreatedAt = time.Now()
+	response.JSON(w, http.StatusCreated, token)
+}
+
+func GetPersonalAccessTokens(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	tokens, err := repositories.NewPersonalAccessTokenRep(db).SearchByUser(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, tokens)
+}
+
+func RevokePersonalAccessToken(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	tokenID, err := strconv.ParseUint(params["tokenId"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	revoked, err := repositories.NewPersonalAccessTokenRep(db).Revoke(tokenID, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !revoked {
+		response.Erro(w, http.StatusNotFound, errors.New("token não encontrado"))
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
@@ -0,0 +1,184 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/config"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"api/src/security"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+//GetTrash lista as publicações que o usuário logado excluiu e ainda pode restaurar
+func GetTrash(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	posts, err := repositories.NewPostRep(db).SearchTrash(userID, time.Now().Add(-config.TrashRetention))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if posts == nil {
+		posts = []models.Post{}
+	}
+
+	setPostURLs(posts)
+	response.JSON(w, http.StatusOK, posts)
+}
+
+//RestorePost tira a publicação da lixeira. O autor só restaura o que ele mesmo excluiu;
+//o que foi removido por um moderador só volta pelas mãos de um admin ou moderador.
+func RestorePost(w http.ResponseWriter, r *http.Request) {
+	claims, err := auth.GetClaims(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	postID, err := strconv.ParseUint(params["idPost"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewPostRep(db)
+	post, err := rep.GetDeleted(postID, time.Now().Add(-config.TrashRetention))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if post.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("publicação não encontrada na lixeira"))
+		return
+	}
+
+	ownTrash := post.AuthorID == claims.UserID && post.DeletedBy == claims.UserID
+	if !ownTrash {
+		if !claims.HasAnyRole(models.RoleAdmin, models.RoleModerator) {
+			response.Erro(w, http.StatusForbidden, errors.New("não é possível restaurar esta publicação"))
+			return
+		}
+
+		if err := audit(db, claims.UserID, "restore_post", "post", postID); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
+	restored, err := rep.Restore(postID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !restored {
+		response.Erro(w, http.StatusConflict, errors.New("a publicação já foi repostada de novo"))
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//RestoreUser tira da lixeira a conta excluída, conferindo o email e a senha como no login.
+//Depois de restaurada a conta entra normalmente pelo login.
+func RestoreUser(w http.ResponseWriter, r *http.Request) {
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var user models.User
+	if err = json.Unmarshal(request, &user); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	account := strings.ToLower(strings.TrimSpace(user.Email))
+	address := clientIP(r)
+
+	wait, err := reserveLogin(account, address)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if wait > 0 {
+		tooManyAttempts(w, wait)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewUserRep(db)
+	deletedUser, err := rep.SearchDeletedByEmail(user.Email, time.Now().Add(-config.TrashRetention))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = security.ValidatePassword(user.Password, deletedUser.Password); err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	if err = loginSucceeded(account, address); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	//a conta excluída pela moderação só volta pelas mãos dela
+	if deletedUser.DeletedBy != deletedUser.ID {
+		response.Erro(w, http.StatusForbidden, errors.New("a conta foi excluída pela moderação e não pode ser restaurada"))
+		return
+	}
+
+	restored, err := rep.Restore(deletedUser.ID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !restored {
+		response.Erro(w, http.StatusConflict, errors.New("o email já pertence a outra conta"))
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
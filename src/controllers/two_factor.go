@@ -0,0 +1,336 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/db"
+	"api/src/models"
+	"api/src/repositories"
+	"api/src/response"
+	"api/src/security"
+	"api/src/throttle"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const recoveryCodesAmount = 10
+
+//EnrollTwoFactor gera um novo segredo TOTP; o 2FA só passa a valer depois de confirmado com um código
+func EnrollTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewTwoFactorRep(db)
+	settings, err := rep.Get(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if settings.Enabled {
+		response.Erro(w, http.StatusConflict, errors.New("a autenticação em dois fatores já está ativada"))
+		return
+	}
+
+	user, err := repositories.NewUserRep(db).GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	secret, err := security.NewTOTPSecret()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = rep.SetPendingSecret(userID, secret); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.TwoFactorEnrollment{
+		Secret: secret,
+		URI:    security.TOTPURI("DevBook", user.Email, secret),
+	})
+}
+
+//ConfirmTwoFactor ativa o 2FA com o primeiro código gerado pelo aplicativo e devolve os códigos de recuperação.
+//Eles só são mostrados nesta resposta.
+func ConfirmTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body models.TwoFactorCode
+	if err = json.Unmarshal(request, &body); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	rep := repositories.NewTwoFactorRep(db)
+	settings, err := rep.Get(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if settings.Enabled {
+		response.Erro(w, http.StatusConflict, errors.New("a autenticação em dois fatores já está ativada"))
+		return
+	}
+
+	if settings.Secret == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("inicie o cadastro da autenticação em dois fatores primeiro"))
+		return
+	}
+
+	step, ok := security.ValidateTOTP(settings.Secret, body.Code, time.Now())
+	if !ok {
+		response.Erro(w, http.StatusUnauthorized, errors.New("código inválido"))
+		return
+	}
+
+	codes, err := security.NewRecoveryCodes(recoveryCodesAmount)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	codeHashes := make([]string, len(codes))
+	for i, code := range codes {
+		codeHashes[i] = auth.HashToken(code)
+	}
+
+	enabled, err := rep.Enable(userID, settings.Secret, step, codeHashes)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !enabled {
+		response.Erro(w, http.StatusConflict, errors.New("o cadastro da autenticação em dois fatores mudou, confirme com um código do novo segredo"))
+		return
+	}
+
+	response.JSON(w, http.StatusOK, models.RecoveryCodes{Codes: codes})
+}
+
+//DisableTwoFactor desativa o 2FA; exige a senha e um código (do aplicativo ou de recuperação).
+//As tentativas contam no mesmo limite do login, senão a rota serviria para testar senhas sem espera.
+func DisableTwoFactor(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body models.TwoFactorCode
+	if err = json.Unmarshal(request, &body); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	userRep := repositories.NewUserRep(db)
+	user, err := userRep.GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	account := strings.ToLower(user.Email)
+	address := clientIP(r)
+
+	wait, err := reserveLogin(account, address)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if wait > 0 {
+		tooManyAttempts(w, wait)
+		return
+	}
+
+	currentPassword, err := userRep.GetCurrentPassword(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = security.ValidatePassword(body.Password, currentPassword); err != nil {
+		response.Erro(w, http.StatusUnauthorized, errors.New("senha incorreta"))
+		return
+	}
+
+	rep := repositories.NewTwoFactorRep(db)
+	settings, err := rep.Get(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !settings.Enabled {
+		response.Erro(w, http.StatusConflict, errors.New("a autenticação em dois fatores não está ativada"))
+		return
+	}
+
+	valid, err := checkSecondFactor(db, userID, settings, body.Code)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !valid {
+		response.Erro(w, http.StatusUnauthorized, errors.New("código inválido"))
+		return
+	}
+
+	//só com a senha e o código certos a tentativa deixa de contar como falha
+	if err = loginSucceeded(account, address); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err = rep.Disable(userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//LoginTwoFactor troca o token de desafio devolvido pelo login e um código válido pelos tokens de acesso
+func LoginTwoFactor(w http.ResponseWriter, r *http.Request) {
+	request, err := io.ReadAll(r.Body)
+	if err != nil {
+		response.Erro(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+
+	var body models.TwoFactorLogin
+	if err = json.Unmarshal(request, &body); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	userID, err := auth.ParseChallengeToken(body.ChallengeToken)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	settings, err := repositories.NewTwoFactorRep(db).Get(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !settings.Enabled {
+		response.Erro(w, http.StatusUnauthorized, errors.New("a autenticação em dois fatores não está ativada"))
+		return
+	}
+
+	//o desafio dura pouco, mas ainda assim daria para testar muitos códigos sem esse limite
+	attemptKey := fmt.Sprintf("2fa:%d", userID)
+	wait, err := throttle.Accounts.Reserve(attemptKey)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if wait > 0 {
+		tooManyAttempts(w, wait)
+		return
+	}
+
+	valid, err := checkSecondFactor(db, userID, settings, body.Code)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !valid {
+		response.Erro(w, http.StatusUnauthorized, errors.New("código inválido"))
+		return
+	}
+
+	if err = throttle.Accounts.Reset(attemptKey); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	authData, err := startSession(db, r, userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, authData)
+}
+
+//checkSecondFactor aceita um código TOTP ainda não usado ou um código de recuperação, que é consumido
+func checkSecondFactor(db *sql.DB, userID uint64, settings models.TwoFactorSettings, code string) (bool, error) {
+	rep := repositories.NewTwoFactorRep(db)
+
+	if step, ok := security.ValidateTOTP(settings.Secret, code, time.Now()); ok {
+		return rep.UseStep(userID, step)
+	}
+
+	code = strings.ToLower(strings.TrimSpace(code))
+	if code == "" {
+		return false, nil
+	}
+
+	return rep.UseRecoveryCode(userID, auth.HashToken(code))
+}
@@ -7,9 +7,11 @@ import (
 	"api/src/repositories"
 	"api/src/response"
 	"api/src/security"
+	"api/src/storage"
 	"encoding/json"
 	"errors"
 	"io"
+	"log"
 	"net/http"
 	"strconv"
 	"strings"
@@ -48,6 +50,10 @@ func CreateUser(w http.ResponseWriter, r *http.Request) {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
+
+	if err := sendVerificationEmail(db, user.ID, user.Email); err != nil {
+		log.Printf("erro ao enviar o email de confirmação: %v", err)
+	}
 	
 	response.JSON(w, http.StatusCreated, user)
 }
@@ -70,6 +76,7 @@ func GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setUserURLs(user)
 	response.JSON(w, http.StatusOK, user)
 }
 
@@ -95,6 +102,7 @@ func GetOneUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	user.SetURLs(storage.URL)
 	response.JSON(w, http.StatusOK, user)
 }
 
@@ -106,14 +114,14 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//Id do usuario que está salvo no token jwt
-	userIdToken, err := auth.GetUserID(r)
+	//dados do usuario que estão salvos no token jwt
+	claims, err := auth.GetClaims(r)
 	if err != nil {
 		response.Erro(w, http.StatusUnauthorized, err)
 		return
 	}
 	
-	if userID != userIdToken {
+	if userID != claims.UserID && !claims.HasAnyRole(models.RoleAdmin, models.RoleModerator) {
 		response.Erro(w, http.StatusForbidden, errors.New("você só pode atualizar seu usuário"))
 		return
 	}
@@ -142,12 +150,47 @@ func UpdateUser(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
+	if !canOverride(w, db, claims, userID) {
+		return
+	}
+
 	rep := repositories.NewUserRep(db)
+	userFromDB, err := rep.GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if userFromDB.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+		return
+	}
+
+	if userID != claims.UserID {
+		//quem troca o email pode pedir o "esqueci minha senha" e tomar a conta, então moderadores não podem trocá-lo
+		if userFromDB.Email != user.Email && !claims.HasAnyRole(models.RoleAdmin) {
+			response.Erro(w, http.StatusForbidden, errors.New("só o dono da conta ou um administrador pode trocar o email"))
+			return
+		}
+
+		if err := audit(db, claims.UserID, "update_user", "user", userID); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	if err := rep.Update(userID, user); err != nil {
 		response.Erro(w, http.StatusNoContent, err)
 		return
 	}
 
+	//o novo email precisa ser confirmado de novo
+	if userFromDB.Email != user.Email {
+		if err := sendVerificationEmail(db, userID, user.Email); err != nil {
+			log.Printf("erro ao enviar o email de confirmação: %v", err)
+		}
+	}
+
 	response.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -159,14 +202,14 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	//Id do usuario que está salvo no token jwt
-	userIdToken, err := auth.GetUserID(r)
+	//dados do usuario que estão salvos no token jwt
+	claims, err := auth.GetClaims(r)
 	if err != nil {
 		response.Erro(w, http.StatusUnauthorized, err)
 		return
 	}
 	
-	if userID != userIdToken {
+	if userID != claims.UserID && !claims.HasAnyRole(models.RoleAdmin, models.RoleModerator) {
 		response.Erro(w, http.StatusForbidden, errors.New("você só pode excluir seu usuário"))
 		return
 	}
@@ -178,13 +221,30 @@ func DeleteUser(w http.ResponseWriter, r *http.Request) {
 	}
 	defer db.Close()
 
+	if !canOverride(w, db, claims, userID) {
+		return
+	}
+
+	if userID != claims.UserID {
+		if err := audit(db, claims.UserID, "delete_user", "user", userID); err != nil {
+			response.Erro(w, http.StatusInternalServerError, err)
+			return
+		}
+	}
+
 	rep := repositories.NewUserRep(db)
-	err = rep.Delete(userID)
+	err = rep.Delete(userID, claims.UserID)
 	if err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
 		return
 	}
 
+	//o usuário fica na lixeira, mas nenhum token dele continua valendo
+	if err = revokeAllTokens(db, userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	response.JSON(w, http.StatusNoContent, nil)
 }
 
@@ -258,6 +318,81 @@ func StopFollowing(w http.ResponseWriter, r *http.Request) {
 	response.JSON(w, http.StatusNoContent, nil)
 }
 
+//BlockUser bloqueia o usuário, que deixa de conseguir mencionar quem o bloqueou
+func BlockUser(w http.ResponseWriter, r *http.Request) {
+	blockerID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if userID == blockerID {
+		response.Erro(w, http.StatusForbidden, errors.New("você não pode bloquear a si mesmo"))
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	user, err := repositories.NewUserRep(db).GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if user.ID == 0 {
+		response.Erro(w, http.StatusNotFound, errors.New("usuário não encontrado"))
+		return
+	}
+
+	if err := repositories.NewBlockRep(db).Block(blockerID, userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+func UnblockUser(w http.ResponseWriter, r *http.Request) {
+	blockerID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	params := mux.Vars(r)
+	userID, err := strconv.ParseUint(params["userId"], 10, 64)
+	if err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	if err := repositories.NewBlockRep(db).Unblock(blockerID, userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
 func Followers(w http.ResponseWriter, r *http.Request)  {
 	params := mux.Vars(r)
 	userID, err := strconv.ParseUint(params["userId"], 10, 64)
@@ -280,6 +415,7 @@ func Followers(w http.ResponseWriter, r *http.Request)  {
 		return
 	}
 
+	setUserURLs(followers)
 	response.JSON(w, http.StatusOK, followers)
 }
 
@@ -305,6 +441,7 @@ func Following(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	setUserURLs(followers)
 	response.JSON(w, http.StatusOK, followers)
 }
 
@@ -339,6 +476,11 @@ func NewPassword(w http.ResponseWriter, r *http.Request){
 		return
 	}
 
+	if password.NewPassword == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("o campo nova senha é obrigatório"))
+		return
+	}
+
 	db, err := db.ConnectDB()
 	if err != nil {
 		response.Erro(w, http.StatusInternalServerError, err)
@@ -359,6 +501,17 @@ func NewPassword(w http.ResponseWriter, r *http.Request){
 		return
 	}
 
+	user, err := rep.GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if err := security.CheckPassword("new_password", password.NewPassword, user.Nick, user.Email); err != nil {
+		response.Erro(w, http.StatusBadRequest, err)
+		return
+	}
+
 	newHashedPassword, err := security.Hash(password.NewPassword)
 	if err != nil {
 		response.Erro(w, http.StatusBadRequest, err)
@@ -370,5 +523,11 @@ func NewPassword(w http.ResponseWriter, r *http.Request){
 		return
 	}
 
+	//a senha mudou, então nenhum token emitido com a senha antiga continua valendo
+	if err := revokeAllTokens(db, userID); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
 	response.JSON(w, http.StatusNoContent, nil)
 }
\ No newline at end of file
@@ -0,0 +1,143 @@
+package controllers
+
+import (
+	"api/src/auth"
+	"api/src/config"
+	"api/src/db"
+	"api/src/mailer"
+	"api/src/repositories"
+	"api/src/response"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+var errEmailNotVerified = errors.New("confirme o seu email antes de continuar")
+
+//VerifyEmail confirma o email do usuário a partir do link enviado por email
+func VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	token := r.URL.Query().Get("token")
+	if token == "" {
+		response.Erro(w, http.StatusBadRequest, errors.New("o token é obrigatório"))
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	userID, email, err := repositories.NewEmailVerificationRep(db).Consume(auth.HashToken(token))
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if userID == 0 {
+		response.Erro(w, http.StatusBadRequest, errors.New("token inválido ou expirado"))
+		return
+	}
+
+	verified, err := repositories.NewUserRep(db).MarkEmailVerified(userID, email)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if !verified {
+		response.Erro(w, http.StatusBadRequest, errors.New("o email da conta mudou depois que este link foi enviado"))
+		return
+	}
+
+	response.JSON(w, http.StatusNoContent, nil)
+}
+
+//ResendVerificationEmail envia um novo link de confirmação para o email atual do usuário logado
+func ResendVerificationEmail(w http.ResponseWriter, r *http.Request) {
+	userID, err := auth.GetUserID(r)
+	if err != nil {
+		response.Erro(w, http.StatusUnauthorized, err)
+		return
+	}
+
+	db, err := db.ConnectDB()
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+	defer db.Close()
+
+	user, err := repositories.NewUserRep(db).GetById(userID)
+	if err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	if user.EmailVerifiedAt != nil {
+		response.Erro(w, http.StatusConflict, errors.New("o seu email já foi confirmado"))
+		return
+	}
+
+	if err = sendVerificationEmail(db, user.ID, user.Email); err != nil {
+		response.Erro(w, http.StatusInternalServerError, err)
+		return
+	}
+
+	response.JSON(w, http.StatusAccepted, nil)
+}
+
+func sendVerificationEmail(db *sql.DB, userID uint64, email string) error {
+	token, err := auth.NewOpaqueToken(32)
+	if err != nil {
+		return err
+	}
+
+	rep := repositories.NewEmailVerificationRep(db)
+	if err = rep.Create(userID, email, auth.HashToken(token), time.Now().Add(config.EmailVerificationTTL)); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s/verify-email?token=%s", config.APIURL, url.QueryEscape(token))
+	return mailer.Send(mailer.Message{
+		To:      email,
+		Subject: "Confirme o seu email no DevBook",
+		Body:    fmt.Sprintf("Para confirmar o seu email, acesse o link abaixo:\n%s\n\nSe você não criou uma conta no DevBook, ignore este email.", link),
+	})
+}
+
+//checkCanPost bloqueia a criação de conteúdo por quem ainda não confirmou o email, se a política exigir
+func checkCanPost(db *sql.DB, userID uint64) error {
+	if config.EmailVerificationPolicy != "post" && config.EmailVerificationPolicy != "login" {
+		return nil
+	}
+
+	user, err := repositories.NewUserRep(db).GetById(userID)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerifiedAt == nil {
+		return errEmailNotVerified
+	}
+
+	return nil
+}
+
+//canPost aplica checkCanPost e escreve a resposta de erro; retorna false se o usuário não pode publicar
+func canPost(w http.ResponseWriter, db *sql.DB, userID uint64) bool {
+	if err := checkCanPost(db, userID); err != nil {
+		if errors.Is(err, errEmailNotVerified) {
+			response.Erro(w, http.StatusForbidden, err)
+			return false
+		}
+		response.Erro(w, http.StatusInternalServerError, err)
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,147 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+//context é quantas linhas iguais aparecem em volta de cada trecho alterado
+const context = 3
+
+type line struct {
+	kind byte
+	text string
+}
+
+//Unified compara os dois textos linha a linha e retorna a diferença no formato unificado, ou "" se forem iguais
+func Unified(fromName, toName, from, to string) string {
+	lines := compare(splitLines(from), splitLines(to))
+
+	var changes []int
+	for i, l := range lines {
+		if l.kind != ' ' {
+			changes = append(changes, i)
+		}
+	}
+
+	if len(changes) == 0 {
+		return ""
+	}
+
+	//fromLine e toLine guardam quantas linhas de cada texto vieram antes de cada posição
+	fromLine := make([]int, len(lines)+1)
+	toLine := make([]int, len(lines)+1)
+	for i, l := range lines {
+		fromLine[i+1], toLine[i+1] = fromLine[i], toLine[i]
+		if l.kind != '+' {
+			fromLine[i+1]++
+		}
+		if l.kind != '-' {
+			toLine[i+1]++
+		}
+	}
+
+	var out strings.Builder
+	fmt.Fprintf(&out, "--- %s\n+++ %s\n", fromName, toName)
+
+	for first := 0; first < len(changes); {
+		last := first
+		//como no diff do GNU, alterações separadas por até 2*context linhas iguais ficam no mesmo trecho
+		for last+1 < len(changes) && changes[last+1]-changes[last] <= 2*context+1 {
+			last++
+		}
+
+		start := changes[first] - context
+		if start < 0 {
+			start = 0
+		}
+		end := changes[last] + context + 1
+		if end > len(lines) {
+			end = len(lines)
+		}
+
+		fmt.Fprintf(&out, "@@ -%s +%s @@\n",
+			hunkRange(fromLine[start], fromLine[end]-fromLine[start]),
+			hunkRange(toLine[start], toLine[end]-toLine[start]),
+		)
+		for _, l := range lines[start:end] {
+			out.WriteByte(l.kind)
+			out.WriteString(l.text)
+			out.WriteByte('\n')
+		}
+
+		first = last + 1
+	}
+
+	return out.String()
+}
+
+//splitLines separa o texto em linhas; o texto vazio não tem nenhuma, como um arquivo vazio para o diff do GNU
+func splitLines(text string) []string {
+	if text == "" {
+		return nil
+	}
+
+	return strings.Split(text, "\n")
+}
+
+//hunkRange escreve o intervalo como o diff do GNU: a linha inicial conta a partir de 1 e um trecho vazio aponta para a linha anterior
+func hunkRange(before, count int) string {
+	switch count {
+	case 0:
+		return fmt.Sprintf("%d,0", before)
+	case 1:
+		return fmt.Sprintf("%d", before+1)
+	default:
+		return fmt.Sprintf("%d,%d", before+1, count)
+	}
+}
+
+//compare monta a lista de linhas iguais, removidas e adicionadas a partir da maior subsequência comum.
+//A tabela ocupa (n+1)×(m+1) inteiros. Uma revisão tem no máximo 50 caracteres de título e 500 de conteúdo,
+//ou seja, pouco mais de 500 linhas, o que dá cerca de 300 mil posições; por isso não vale usar um algoritmo
+//mais econômico, mas textos sem esse limite precisariam de outro.
+func compare(from, to []string) []line {
+	common := make([][]int, len(from)+1)
+	for i := range common {
+		common[i] = make([]int, len(to)+1)
+	}
+
+	for i := len(from) - 1; i >= 0; i-- {
+		for j := len(to) - 1; j >= 0; j-- {
+			if from[i] == to[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else if common[i+1][j] >= common[i][j+1] {
+				common[i][j] = common[i+1][j]
+			} else {
+				common[i][j] = common[i][j+1]
+			}
+		}
+	}
+
+	var lines []line
+	i, j := 0, 0
+	for i < len(from) && j < len(to) {
+		switch {
+		case from[i] == to[j]:
+			lines = append(lines, line{' ', from[i]})
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, line{'-', from[i]})
+			i++
+		default:
+			lines = append(lines, line{'+', to[j]})
+			j++
+		}
+	}
+
+	for ; i < len(from); i++ {
+		lines = append(lines, line{'-', from[i]})
+	}
+	for ; j < len(to); j++ {
+		lines = append(lines, line{'+', to[j]})
+	}
+
+	return lines
+}
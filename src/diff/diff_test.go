@@ -0,0 +1,103 @@
+package diff
+
+import "testing"
+
+func TestUnified(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to string
+		want     string
+	}{
+		{
+			name: "textos iguais",
+			from: "a\nb",
+			to:   "a\nb",
+			want: "",
+		},
+		{
+			name: "linha trocada",
+			from: "a\nb\nc",
+			to:   "a\nx\nc",
+			want: "--- v1\n+++ v2\n@@ -1,3 +1,3 @@\n a\n-b\n+x\n c\n",
+		},
+		{
+			name: "linha adicionada no fim",
+			from: "a\nb",
+			to:   "a\nb\nc",
+			want: "--- v1\n+++ v2\n@@ -1,2 +1,3 @@\n a\n b\n+c\n",
+		},
+		{
+			name: "linha removida do começo e outra adicionada no fim",
+			from: "a\nb\nc",
+			to:   "b\nc\nd",
+			want: "--- v1\n+++ v2\n@@ -1,3 +1,3 @@\n-a\n b\n c\n+d\n",
+		},
+		{
+			name: "tudo removido",
+			from: "a",
+			to:   "",
+			want: "--- v1\n+++ v2\n@@ -1 +0,0 @@\n-a\n",
+		},
+		{
+			name: "texto novo a partir do vazio",
+			from: "",
+			to:   "a\nb",
+			want: "--- v1\n+++ v2\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		{
+			name: "dois textos vazios",
+			from: "",
+			to:   "",
+			want: "",
+		},
+		{
+			name: "alterações distantes viram trechos separados",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10",
+			to:   "X\n2\n3\n4\n5\n6\n7\n8\n9\nY",
+			want: "--- v1\n+++ v2\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+X\n 2\n 3\n 4\n" +
+				"@@ -7,4 +7,4 @@\n 7\n 8\n 9\n-10\n+Y\n",
+		},
+		{
+			name: "alterações próximas ficam no mesmo trecho",
+			from: "1\n2\n3\n4\n5\n6\n7\n8",
+			to:   "X\n2\n3\n4\n5\n6\n7\nY",
+			want: "--- v1\n+++ v2\n@@ -1,8 +1,8 @@\n-1\n+X\n 2\n 3\n 4\n 5\n 6\n 7\n-8\n+Y\n",
+		},
+		{
+			name: "sete linhas iguais já separam os trechos",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9",
+			to:   "X\n2\n3\n4\n5\n6\n7\n8\nY",
+			want: "--- v1\n+++ v2\n" +
+				"@@ -1,4 +1,4 @@\n-1\n+X\n 2\n 3\n 4\n" +
+				"@@ -6,4 +6,4 @@\n 6\n 7\n 8\n-9\n+Y\n",
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := Unified("v1", "v2", test.from, test.to); got != test.want {
+				t.Errorf("Unified() =\n%s\nesperado\n%s", got, test.want)
+			}
+		})
+	}
+}
+
+func TestHunkRange(t *testing.T) {
+	tests := []struct {
+		before, count int
+		want          string
+	}{
+		{0, 0, "0,0"},
+		{4, 0, "4,0"},
+		{0, 1, "1"},
+		{6, 1, "7"},
+		{6, 4, "7,4"},
+	}
+
+	for _, test := range tests {
+		if got := hunkRange(test.before, test.count); got != test.want {
+			t.Errorf("hunkRange(%d, %d) = %q, esperado %q", test.before, test.count, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package mailer
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+//File substitui o envio de emails em desenvolvimento: cada mensagem vira um arquivo .eml em Dir e uma linha no log
+type File struct {
+	Dir  string
+	From string
+}
+
+func (f File) Send(message Message) error {
+	log.Printf("\n email para %s: %s", message.To, message.Subject)
+
+	if f.Dir == "" {
+		return nil
+	}
+
+	if err := os.MkdirAll(f.Dir, 0o755); err != nil {
+		return err
+	}
+
+	name := fmt.Sprintf("%d-%s.eml", time.Now().UnixNano(), filepath.Base(message.To))
+	return os.WriteFile(filepath.Join(f.Dir, name), format(f.From, message), 0o644)
+}
@@ -0,0 +1,45 @@
+package mailer
+
+import (
+	"api/src/config"
+	"sync"
+)
+
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+//Mailer é qualquer forma de entregar um email; em produção SMTP, em desenvolvimento arquivos locais
+type Mailer interface {
+	Send(message Message) error
+}
+
+//Default é o mailer usado por Send. Se não for definido, é escolhido pela configuração MAILER no primeiro envio.
+var Default Mailer
+
+var once sync.Once
+
+func Send(message Message) error {
+	once.Do(func() {
+		if Default != nil {
+			return
+		}
+
+		switch config.Mailer {
+		case "smtp":
+			Default = SMTP{
+				Host:     config.SMTPHost,
+				Port:     config.SMTPPort,
+				User:     config.SMTPUser,
+				Password: config.SMTPPassword,
+				From:     config.MailFrom,
+			}
+		default:
+			Default = File{Dir: config.MailDir, From: config.MailFrom}
+		}
+	})
+
+	return Default.Send(message)
+}
@@ -0,0 +1,37 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+)
+
+type SMTP struct {
+	Host     string
+	Port     int
+	User     string
+	Password string
+	From     string
+}
+
+func (s SMTP) Send(message Message) error {
+	var auth smtp.Auth
+	if s.User != "" {
+		auth = smtp.PlainAuth("", s.User, s.Password, s.Host)
+	}
+
+	return smtp.SendMail(fmt.Sprintf("%s:%d", s.Host, s.Port), auth, s.From, []string{message.To}, format(s.From, message))
+}
+
+func format(from string, message Message) []byte {
+	var builder strings.Builder
+	builder.WriteString("From: " + from + "\r\n")
+	builder.WriteString("To: " + message.To + "\r\n")
+	builder.WriteString("Subject: " + message.Subject + "\r\n")
+	builder.WriteString("MIME-Version: 1.0\r\n")
+	builder.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	builder.WriteString("\r\n")
+	builder.WriteString(message.Body)
+
+	return []byte(builder.String())
+}
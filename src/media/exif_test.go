@@ -0,0 +1,107 @@
+package media
+
+import (
+	"encoding/binary"
+	"image"
+	"image/color"
+	"testing"
+)
+
+//exifJPEG monta o começo de um JPEG com um segmento APP1 cujo EXIF tem só a tag Orientation
+func exifJPEG(order binary.ByteOrder, orientation uint16) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], 0x0112)
+	order.PutUint16(tiff[12:], 3)
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(data[4:], uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0, 2)
+}
+
+func TestJPEGOrientation(t *testing.T) {
+	truncated := exifJPEG(binary.BigEndian, 6)
+
+	tests := []struct {
+		name string
+		data []byte
+		want int
+	}{
+		{"big endian", exifJPEG(binary.BigEndian, 6), 6},
+		{"little endian", exifJPEG(binary.LittleEndian, 8), 8},
+		{"orientação normal", exifJPEG(binary.BigEndian, 1), 1},
+		{"orientação fora do intervalo", exifJPEG(binary.BigEndian, 9), 1},
+		{"segmento cortado", truncated[:20], 1},
+		{"JPEG sem EXIF", []byte{0xFF, 0xD8, 0xFF, 0xDA, 0, 2}, 1},
+		{"não é JPEG", []byte("\x89PNG\r\n\x1a\n"), 1},
+		{"vazio", nil, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := jpegOrientation(test.data); got != test.want {
+				t.Errorf("jpegOrientation() = %d, esperado %d", got, test.want)
+			}
+		})
+	}
+}
+
+//pixel marca cada posição com uma cor diferente para que dê para seguir para onde ela foi
+func pixel(x, y int) color.RGBA {
+	return color.RGBA{R: uint8(x), G: uint8(y), A: 255}
+}
+
+func TestOrient(t *testing.T) {
+	//imagem 3x2; want diz, para cada pixel do resultado, de qual posição da original ele veio
+	src := image.NewRGBA(image.Rect(0, 0, 3, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 3; x++ {
+			src.SetRGBA(x, y, pixel(x, y))
+		}
+	}
+
+	type point struct{ x, y int }
+	tests := []struct {
+		orientation int
+		want        [][]point
+	}{
+		{1, [][]point{{{0, 0}, {1, 0}, {2, 0}}, {{0, 1}, {1, 1}, {2, 1}}}},
+		{2, [][]point{{{2, 0}, {1, 0}, {0, 0}}, {{2, 1}, {1, 1}, {0, 1}}}},
+		{3, [][]point{{{2, 1}, {1, 1}, {0, 1}}, {{2, 0}, {1, 0}, {0, 0}}}},
+		{4, [][]point{{{0, 1}, {1, 1}, {2, 1}}, {{0, 0}, {1, 0}, {2, 0}}}},
+		{5, [][]point{{{0, 0}, {0, 1}}, {{1, 0}, {1, 1}}, {{2, 0}, {2, 1}}}},
+		{6, [][]point{{{0, 1}, {0, 0}}, {{1, 1}, {1, 0}}, {{2, 1}, {2, 0}}}},
+		{7, [][]point{{{2, 1}, {2, 0}}, {{1, 1}, {1, 0}}, {{0, 1}, {0, 0}}}},
+		{8, [][]point{{{2, 0}, {2, 1}}, {{1, 0}, {1, 1}}, {{0, 0}, {0, 1}}}},
+		{9, [][]point{{{0, 0}, {1, 0}, {2, 0}}, {{0, 1}, {1, 1}, {2, 1}}}},
+	}
+
+	for _, test := range tests {
+		got := orient(src, test.orientation)
+
+		if got.Rect.Dx() != len(test.want[0]) || got.Rect.Dy() != len(test.want) {
+			t.Errorf("orient(%d) gerou %dx%d, esperado %dx%d", test.orientation, got.Rect.Dx(), got.Rect.Dy(), len(test.want[0]), len(test.want))
+			continue
+		}
+
+		for y, row := range test.want {
+			for x, from := range row {
+				if got.RGBAAt(x, y) != pixel(from.x, from.y) {
+					t.Errorf("orient(%d): o pixel (%d, %d) deveria vir de (%d, %d), veio %v", test.orientation, x, y, from.x, from.y, got.RGBAAt(x, y))
+				}
+			}
+		}
+	}
+}
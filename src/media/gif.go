@@ -0,0 +1,112 @@
+package media
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+//limites das animações: cada quadro decodificado ocupa largura × altura bytes, então um GIF pequeno com
+//milhares de quadros do tamanho da tela também se expandiria em gigabytes
+const (
+	maxFrames          = 300
+	maxAnimationPixels = 100_000_000
+)
+
+var errInvalidGIF = errors.New("imagem inválida: GIF corrompido")
+
+//gifFrames percorre os blocos do GIF sem decodificar os pixels e retorna quantos quadros ele tem e a soma
+//de largura × altura de todos eles
+func gifFrames(data []byte) (int, int, error) {
+	//cabeçalho (6 bytes) e descritor da tela (7 bytes)
+	if len(data) < 13 {
+		return 0, 0, errInvalidGIF
+	}
+
+	i := 13
+	if flags := data[10]; flags&0x80 != 0 {
+		i += 3 << (flags&0x07 + 1)
+	}
+
+	frames, pixels := 0, 0
+	for i < len(data) {
+		switch data[i] {
+		//trailer: fim do arquivo
+		case 0x3B:
+			return frames, pixels, nil
+		//extensão: rótulo seguido de sub-blocos
+		case 0x21:
+			if i+2 > len(data) {
+				return 0, 0, errInvalidGIF
+			}
+
+			next, err := skipSubBlocks(data, i+2)
+			if err != nil {
+				return 0, 0, err
+			}
+			i = next
+		//descritor de imagem: posição, tamanho, tabela de cores local, tamanho mínimo do código LZW e sub-blocos
+		case 0x2C:
+			if i+11 > len(data) {
+				return 0, 0, errInvalidGIF
+			}
+
+			width := int(binary.LittleEndian.Uint16(data[i+5:]))
+			height := int(binary.LittleEndian.Uint16(data[i+7:]))
+			flags := data[i+9]
+
+			frames++
+			pixels += width * height
+
+			i += 10
+			if flags&0x80 != 0 {
+				i += 3 << (flags&0x07 + 1)
+			}
+
+			next, err := skipSubBlocks(data, i+1)
+			if err != nil {
+				return 0, 0, err
+			}
+			i = next
+		default:
+			return 0, 0, errInvalidGIF
+		}
+	}
+
+	//sem trailer o decodificador ainda aceita os quadros que vieram inteiros
+	return frames, pixels, nil
+}
+
+//skipSubBlocks pula uma sequência de sub-blocos (um byte de tamanho seguido dos dados) até o bloco vazio que a encerra
+func skipSubBlocks(data []byte, i int) (int, error) {
+	for {
+		if i >= len(data) {
+			return 0, errInvalidGIF
+		}
+
+		size := int(data[i])
+		i++
+		if size == 0 {
+			return i, nil
+		}
+		i += size
+	}
+}
+
+//checkAnimation recusa animações com quadros demais antes que gif.DecodeAll aloque todos eles
+func checkAnimation(data []byte) error {
+	frames, pixels, err := gifFrames(data)
+	if err != nil {
+		return err
+	}
+
+	if frames > maxFrames {
+		return fmt.Errorf("a animação pode ter no máximo %d quadros", maxFrames)
+	}
+
+	if pixels > maxAnimationPixels {
+		return fmt.Errorf("a animação pode ter no máximo %d megapixels somando todos os quadros", maxAnimationPixels/1_000_000)
+	}
+
+	return nil
+}
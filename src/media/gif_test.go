@@ -0,0 +1,92 @@
+package media
+
+import (
+	"bytes"
+	"image"
+	"image/color"
+	"image/gif"
+	"testing"
+)
+
+func encodeGIF(t *testing.T, frames, width, height int) []byte {
+	t.Helper()
+
+	palette := color.Palette{color.Black, color.White}
+	animation := &gif.GIF{}
+	for i := 0; i < frames; i++ {
+		animation.Image = append(animation.Image, image.NewPaletted(image.Rect(0, 0, width, height), palette))
+		animation.Delay = append(animation.Delay, 10)
+	}
+
+	var out bytes.Buffer
+	if err := gif.EncodeAll(&out, animation); err != nil {
+		t.Fatal(err)
+	}
+
+	return out.Bytes()
+}
+
+//rawGIF monta só a estrutura de blocos de um GIF com quadros vazios do tamanho informado, o bastante para
+//gifFrames, que não decodifica os pixels
+func rawGIF(frames, width, height int) []byte {
+	data := []byte("GIF89a")
+	data = append(data, byte(width), byte(width>>8), byte(height), byte(height>>8), 0, 0, 0)
+	for i := 0; i < frames; i++ {
+		data = append(data, 0x2C, 0, 0, 0, 0, byte(width), byte(width>>8), byte(height), byte(height>>8), 0, 2, 0)
+	}
+	return append(data, 0x3B)
+}
+
+func TestGIFFrames(t *testing.T) {
+	animation := encodeGIF(t, 5, 30, 20)
+
+	tests := []struct {
+		name       string
+		data       []byte
+		wantFrames int
+		wantPixels int
+		wantErr    bool
+	}{
+		{"animação", animation, 5, 5 * 30 * 20, false},
+		{"um quadro", encodeGIF(t, 1, 64, 64), 1, 64 * 64, false},
+		{"sem trailer", animation[:len(animation)-1], 5, 5 * 30 * 20, false},
+		{"cortado no meio de um quadro", animation[:len(animation)/2], 0, 0, true},
+		{"bloco desconhecido", append(append([]byte{}, animation[:len(animation)-1]...), 0x00), 0, 0, true},
+		{"só o cabeçalho", animation[:6], 0, 0, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			frames, pixels, err := gifFrames(test.data)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("gifFrames() erro = %v, esperado erro: %v", err, test.wantErr)
+			}
+			if frames != test.wantFrames || pixels != test.wantPixels {
+				t.Errorf("gifFrames() = (%d, %d), esperado (%d, %d)", frames, pixels, test.wantFrames, test.wantPixels)
+			}
+		})
+	}
+}
+
+func TestCheckAnimation(t *testing.T) {
+	tests := []struct {
+		name    string
+		data    []byte
+		wantErr bool
+	}{
+		{"dentro dos limites", encodeGIF(t, 3, 10, 10), false},
+		{"no limite de quadros", encodeGIF(t, maxFrames, 1, 1), false},
+		{"quadros demais", encodeGIF(t, maxFrames+1, 1, 1), true},
+		{"no limite de pixels", rawGIF(4, 5000, 5000), false},
+		{"pixels demais somando os quadros", rawGIF(5, 5000, 5000), true},
+		{"corrompido", []byte("GIF89a"), true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if err := checkAnimation(test.data); (err != nil) != test.wantErr {
+				t.Errorf("checkAnimation() erro = %v, esperado erro: %v", err, test.wantErr)
+			}
+		})
+	}
+}
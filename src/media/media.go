@@ -0,0 +1,193 @@
+package media
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"net/http"
+)
+
+//limites das imagens aceitas: maxPixels evita que um arquivo pequeno se expanda em gigabytes na memória
+const (
+	maxPixels     = 40_000_000
+	thumbnailSize = 320
+	jpegQuality   = 90
+)
+
+//tipos aceitos por enquanto; outros arquivos entram aqui quando houver como limpar os metadados deles
+var extensions = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+}
+
+var ErrUnsupported = errors.New("tipo de arquivo não suportado, envie uma imagem JPEG, PNG ou GIF")
+
+//File é um arquivo pronto para ser guardado, já sem metadados
+type File struct {
+	ContentType string
+	Extension   string
+	Data        []byte
+}
+
+//Image é a imagem enviada, regravada sem EXIF e outros metadados, junto com a miniatura dela
+type Image struct {
+	File
+	Width     int
+	Height    int
+	Thumbnail File
+}
+
+//Process descobre o tipo pelo conteúdo, sem confiar no nome nem no Content-Type do envio, e regrava a imagem.
+//Regravar descarta todos os metadados (EXIF, XMP, comentários); a orientação do EXIF é aplicada antes nos pixels.
+func Process(data []byte) (Image, error) {
+	contentType, config, err := inspect(data)
+	if err != nil {
+		return Image{}, err
+	}
+
+	var out bytes.Buffer
+	var first image.Image
+
+	switch contentType {
+	case "image/gif":
+		if err = checkAnimation(data); err != nil {
+			return Image{}, err
+		}
+
+		//GIF não tem EXIF; regravar todos os quadros mantém a animação e descarta comentários e extensões
+		animation, err := gif.DecodeAll(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, fmt.Errorf("imagem inválida: %w", err)
+		}
+
+		if err = gif.EncodeAll(&out, &gif.GIF{
+			Image:           animation.Image,
+			Delay:           animation.Delay,
+			LoopCount:       animation.LoopCount,
+			Disposal:        animation.Disposal,
+			Config:          animation.Config,
+			BackgroundIndex: animation.BackgroundIndex,
+		}); err != nil {
+			return Image{}, err
+		}
+		first = animation.Image[0]
+	default:
+		decoded, _, err := image.Decode(bytes.NewReader(data))
+		if err != nil {
+			return Image{}, fmt.Errorf("imagem inválida: %w", err)
+		}
+
+		if contentType == "image/jpeg" {
+			decoded = orient(toRGBA(decoded), jpegOrientation(data))
+			err = jpeg.Encode(&out, decoded, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			err = png.Encode(&out, decoded)
+		}
+		if err != nil {
+			return Image{}, err
+		}
+		first = decoded
+	}
+
+	thumbnail, err := encodeThumbnail(first, contentType)
+	if err != nil {
+		return Image{}, err
+	}
+
+	bounds := first.Bounds()
+	if contentType == "image/gif" {
+		bounds = image.Rect(0, 0, config.Width, config.Height)
+	}
+
+	return Image{
+		File:      File{ContentType: contentType, Extension: extensions[contentType], Data: out.Bytes()},
+		Width:     bounds.Dx(),
+		Height:    bounds.Dy(),
+		Thumbnail: thumbnail,
+	}, nil
+}
+
+//Variants recorta a imagem no centro na proporção ratioWidth:ratioHeight e gera uma versão para cada largura de widths,
+//na mesma ordem. De um GIF animado só o primeiro quadro é usado.
+func Variants(data []byte, ratioWidth, ratioHeight int, widths []int) ([]File, error) {
+	contentType, _, err := inspect(data)
+	if err != nil {
+		return nil, err
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("imagem inválida: %w", err)
+	}
+
+	img := toRGBA(decoded)
+	if contentType == "image/jpeg" {
+		img = orient(img, jpegOrientation(data))
+	}
+	img = crop(img, ratioWidth, ratioHeight)
+
+	files := make([]File, len(widths))
+	for i, width := range widths {
+		if files[i], err = encode(Resize(img, width), contentType); err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+//inspect descobre o tipo pelo conteúdo e confere o tamanho da imagem sem decodificá-la
+func inspect(data []byte) (string, image.Config, error) {
+	contentType := http.DetectContentType(data)
+	if _, ok := extensions[contentType]; !ok {
+		return "", image.Config{}, ErrUnsupported
+	}
+
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return "", image.Config{}, fmt.Errorf("imagem inválida: %w", err)
+	}
+
+	if config.Width <= 0 || config.Height <= 0 || config.Width*config.Height > maxPixels {
+		return "", image.Config{}, fmt.Errorf("a imagem pode ter no máximo %d megapixels", maxPixels/1_000_000)
+	}
+
+	return contentType, config, nil
+}
+
+func encodeThumbnail(img image.Image, contentType string) (File, error) {
+	return encode(Resize(img, thumbnailSize), contentType)
+}
+
+//encode grava as versões reduzidas em JPEG; PNG e GIF, que podem ter transparência, viram PNG
+func encode(img image.Image, contentType string) (File, error) {
+	var out bytes.Buffer
+	if contentType == "image/jpeg" {
+		if err := jpeg.Encode(&out, img, &jpeg.Options{Quality: jpegQuality}); err != nil {
+			return File{}, err
+		}
+		return File{ContentType: "image/jpeg", Extension: "jpg", Data: out.Bytes()}, nil
+	}
+
+	if err := png.Encode(&out, img); err != nil {
+		return File{}, err
+	}
+	return File{ContentType: "image/png", Extension: "png", Data: out.Bytes()}, nil
+}
+
+func toRGBA(img image.Image) *image.RGBA {
+	if rgba, ok := img.(*image.RGBA); ok && rgba.Rect.Min == (image.Point{}) {
+		return rgba
+	}
+
+	bounds := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, bounds.Dx(), bounds.Dy()))
+	draw.Draw(rgba, rgba.Rect, img, bounds.Min, draw.Src)
+	return rgba
+}
@@ -0,0 +1,78 @@
+package media
+
+import "image"
+
+//Resize reduz a imagem para que o maior lado tenha no máximo size pixels, mantendo a proporção.
+//Cada pixel novo é a média dos pixels que ele cobre na original. Imagens menores só são copiadas.
+func Resize(img image.Image, size int) *image.RGBA {
+	src := toRGBA(img)
+	width, height := src.Rect.Dx(), src.Rect.Dy()
+
+	if width <= size && height <= size {
+		return src
+	}
+
+	newWidth, newHeight := size, (height*size+width/2)/width
+	if height > width {
+		newWidth, newHeight = (width*size+height/2)/height, size
+	}
+	if newWidth < 1 {
+		newWidth = 1
+	}
+	if newHeight < 1 {
+		newHeight = 1
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, newWidth, newHeight))
+
+	for y := 0; y < newHeight; y++ {
+		y0, y1 := y*height/newHeight, (y+1)*height/newHeight
+		if y1 == y0 {
+			y1 = y0 + 1
+		}
+
+		for x := 0; x < newWidth; x++ {
+			x0, x1 := x*width/newWidth, (x+1)*width/newWidth
+			if x1 == x0 {
+				x1 = x0 + 1
+			}
+
+			var r, g, b, a, count uint64
+			for sy := y0; sy < y1; sy++ {
+				row := src.Pix[sy*src.Stride:]
+				for sx := x0; sx < x1; sx++ {
+					pixel := row[sx*4 : sx*4+4]
+					r += uint64(pixel[0])
+					g += uint64(pixel[1])
+					b += uint64(pixel[2])
+					a += uint64(pixel[3])
+					count++
+				}
+			}
+
+			offset := y*dst.Stride + x*4
+			dst.Pix[offset] = uint8(r / count)
+			dst.Pix[offset+1] = uint8(g / count)
+			dst.Pix[offset+2] = uint8(b / count)
+			dst.Pix[offset+3] = uint8(a / count)
+		}
+	}
+
+	return dst
+}
+
+//crop corta as bordas da imagem para deixá-la na proporção ratioWidth:ratioHeight, mantendo o centro
+func crop(img *image.RGBA, ratioWidth, ratioHeight int) *image.RGBA {
+	width, height := img.Rect.Dx(), img.Rect.Dy()
+
+	cropWidth, cropHeight := width, width*ratioHeight/ratioWidth
+	if cropHeight > height {
+		cropWidth, cropHeight = height*ratioWidth/ratioHeight, height
+	}
+	if cropWidth < 1 || cropHeight < 1 {
+		return img
+	}
+
+	x, y := (width-cropWidth)/2, (height-cropHeight)/2
+	return toRGBA(img.SubImage(image.Rect(x, y, x+cropWidth, y+cropHeight)))
+}
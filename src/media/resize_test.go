@@ -0,0 +1,99 @@
+package media
+
+import (
+	"image"
+	"image/color"
+	"testing"
+)
+
+func TestResize(t *testing.T) {
+	tests := []struct {
+		name                  string
+		width, height, size   int
+		wantWidth, wantHeight int
+	}{
+		{"paisagem", 1000, 500, 320, 320, 160},
+		{"retrato", 500, 1000, 320, 160, 320},
+		{"quadrada", 800, 800, 48, 48, 48},
+		{"arredonda a proporção", 1000, 333, 320, 320, 107},
+		{"menor que o limite não muda", 200, 100, 320, 200, 100},
+		{"lado fino não some", 4000, 3, 320, 320, 1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got := Resize(image.NewRGBA(image.Rect(0, 0, test.width, test.height)), test.size)
+			if got.Rect.Dx() != test.wantWidth || got.Rect.Dy() != test.wantHeight {
+				t.Errorf("Resize() gerou %dx%d, esperado %dx%d", got.Rect.Dx(), got.Rect.Dy(), test.wantWidth, test.wantHeight)
+			}
+		})
+	}
+}
+
+func TestResizeAverage(t *testing.T) {
+	//metade esquerda preta e direita branca: reduzida para 2x1 cada lado mantém a sua cor,
+	//e para 1x1 o pixel é a média das duas
+	src := image.NewRGBA(image.Rect(0, 0, 4, 2))
+	for y := 0; y < 2; y++ {
+		for x := 0; x < 4; x++ {
+			value := uint8(0)
+			if x >= 2 {
+				value = 200
+			}
+			src.SetRGBA(x, y, color.RGBA{value, value, value, 255})
+		}
+	}
+
+	half := Resize(src, 2)
+	if got := half.RGBAAt(0, 0); got != (color.RGBA{0, 0, 0, 255}) {
+		t.Errorf("pixel esquerdo = %v, esperado preto", got)
+	}
+	if got := half.RGBAAt(1, 0); got != (color.RGBA{200, 200, 200, 255}) {
+		t.Errorf("pixel direito = %v, esperado branco", got)
+	}
+
+	single := Resize(src, 1)
+	if got := single.RGBAAt(0, 0); got != (color.RGBA{100, 100, 100, 255}) {
+		t.Errorf("pixel único = %v, esperado a média cinza", got)
+	}
+}
+
+func TestCrop(t *testing.T) {
+	tests := []struct {
+		name                    string
+		width, height           int
+		ratioWidth, ratioHeight int
+		want                    image.Rectangle
+	}{
+		{"paisagem para quadrado", 300, 100, 1, 1, image.Rect(100, 0, 200, 100)},
+		{"retrato para quadrado", 100, 300, 1, 1, image.Rect(0, 100, 100, 200)},
+		{"quadrado para 3:1", 300, 300, 3, 1, image.Rect(0, 100, 300, 200)},
+		{"já na proporção", 600, 200, 3, 1, image.Rect(0, 0, 600, 200)},
+		{"recorte vazio devolve a imagem", 2, 1, 1, 3, image.Rect(0, 0, 2, 1)},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			src := image.NewRGBA(image.Rect(0, 0, test.width, test.height))
+			for y := 0; y < test.height; y++ {
+				for x := 0; x < test.width; x++ {
+					src.SetRGBA(x, y, color.RGBA{uint8(x), uint8(y), 0, 255})
+				}
+			}
+
+			got := crop(src, test.ratioWidth, test.ratioHeight)
+			if got.Rect.Dx() != test.want.Dx() || got.Rect.Dy() != test.want.Dy() {
+				t.Fatalf("crop() gerou %dx%d, esperado %dx%d", got.Rect.Dx(), got.Rect.Dy(), test.want.Dx(), test.want.Dy())
+			}
+			if got.Rect.Min != (image.Point{}) {
+				t.Errorf("crop() começa em %v, esperado a origem", got.Rect.Min)
+			}
+
+			//o canto do recorte tem que ser o pixel do centro da original
+			want := color.RGBA{uint8(test.want.Min.X), uint8(test.want.Min.Y), 0, 255}
+			if corner := got.RGBAAt(0, 0); corner != want {
+				t.Errorf("canto do recorte = %v, esperado %v", corner, want)
+			}
+		})
+	}
+}
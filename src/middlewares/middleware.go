@@ -2,11 +2,17 @@ package middlewares
 
 import (
 	"api/src/auth"
+	"api/src/db"
+	"api/src/repositories"
 	"api/src/response"
+	"errors"
 	"log"
 	"net/http"
+	"time"
 )
 
+const sessionTouchInterval = time.Minute
+
 func Logger(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request){
 		log.Printf("\n %s %s %s", r.Method, r.RequestURI, r.Host)
@@ -16,11 +22,96 @@ func Logger(next http.HandlerFunc) http.HandlerFunc {
 
 func Authenticate(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request){
-		if err := auth.ValidateToken(r); err != nil {
+		claims, err := auth.GetClaims(r)
+		if err != nil {
+			response.Erro(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		//personal access tokens já são conferidos contra o banco quando lidos
+		if claims.PersonalAccessTokenID == 0 {
+			revoked, err := isRevoked(claims)
+			if err != nil {
+				response.Erro(w, http.StatusInternalServerError, err)
+				return
+			}
+
+			if revoked {
+				response.Erro(w, http.StatusUnauthorized, errors.New("token revogado, faça login novamente"))
+				return
+			}
+		}
+
+		next(w, auth.WithClaims(r, claims))
+	}
+}
+
+//Authorize só deixa passar usuários com pelo menos um dos papéis exigidos pela rota
+func Authorize(roles []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request){
+		claims, err := auth.GetClaims(r)
+		if err != nil {
+			response.Erro(w, http.StatusUnauthorized, err)
+			return
+		}
+
+		if !claims.HasAnyRole(roles...) {
+			response.Erro(w, http.StatusForbidden, errors.New("você não tem permissão para acessar este recurso"))
+			return
+		}
+
+		next(w, r)
+	}
+}
+
+//RequireScopes recusa tokens com escopo (como os personal access tokens) que não tenham todos os escopos da rota,
+//inclusive nas rotas que não declaram escopo nenhum
+func RequireScopes(scopes []string, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request){
+		claims, err := auth.GetClaims(r)
+		if err != nil {
 			response.Erro(w, http.StatusUnauthorized, err)
 			return
 		}
+
+		if !claims.HasScopes(scopes...) {
+			response.Erro(w, http.StatusForbidden, errors.New("o token usado não tem permissão para acessar este recurso"))
+			return
+		}
+
 		next(w, r)
 	}
 }
 
+//isRevoked consulta a lista de revogação e a sessão do token; a conexão é fechada antes de seguir para o controller
+func isRevoked(claims auth.Claims) (bool, error) {
+	db, err := db.ConnectDB()
+	if err != nil {
+		return false, err
+	}
+	defer db.Close()
+
+	revoked, err := repositories.NewRevokedTokenRep(db).IsRevoked(claims.TokenID, claims.UserID, claims.IssuedAt)
+	if err != nil || revoked || claims.SessionID == 0 {
+		return revoked, err
+	}
+
+	sessions := repositories.NewSessionRep(db)
+	session, err := sessions.GetByID(claims.SessionID)
+	if err != nil {
+		return false, err
+	}
+
+	if session.ID == 0 || session.RevokedAt != nil {
+		return true, nil
+	}
+
+	//o último acesso só é gravado uma vez por minuto para não escrever no banco a cada requisição
+	if time.Since(session.LastSeenAt) > sessionTouchInterval {
+		if err = sessions.Touch(session.ID); err != nil {
+			return false, err
+		}
+	}
+
+	return false, nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+//MaxAttachments é quantos arquivos uma publicação pode ter
+const MaxAttachments = 4
+
+//Attachment é um arquivo anexado a uma publicação. As chaves apontam para o arquivo e a miniatura no armazenamento.
+type Attachment struct {
+	ID           uint64    `json:"id"`
+	PostID       uint64    `json:"-"`
+	Key          string    `json:"-"`
+	ThumbnailKey string    `json:"-"`
+	ContentType  string    `json:"content_type"`
+	Size         int64     `json:"size"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	URL          string    `json:"url"`
+	ThumbnailURL string    `json:"thumbnail_url,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+//SetURLs monta os endereços do arquivo e da miniatura com a função url do armazenamento
+func (a *Attachment) SetURLs(url func(key string) string) {
+	a.URL = url(a.Key)
+	if a.ThumbnailKey != "" {
+		a.ThumbnailURL = url(a.ThumbnailKey)
+	}
+}
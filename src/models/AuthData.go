@@ -1,6 +1,7 @@
 package models
 
 type AuthData struct {
-	ID    string `json:"id"`
-	Token string `json:"token"`
-}
\ No newline at end of file
+	ID           string `json:"id"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
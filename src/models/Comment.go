@@ -0,0 +1,34 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+type Comment struct {
+	ID         uint64     `json:"id,omitempty"`
+	PostID     uint64     `json:"post_id,omitempty"`
+	ParentID   *uint64    `json:"parent_id,omitempty"`
+	AuthorID   uint64     `json:"author_id,omitempty"`
+	AuthorNick string     `json:"author_nick,omitempty"`
+	Content    string     `json:"content,omitempty"`
+	Replies    uint64     `json:"replies"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	UpdatedAt  *time.Time `json:"updated_at,omitempty"`
+}
+
+func (comment *Comment) Prepare() error {
+	comment.Content = strings.TrimSpace(comment.Content)
+
+	if comment.Content == "" {
+		return errors.New("o comentário precisa ter um conteudo")
+	}
+
+	if utf8.RuneCountInString(comment.Content) > 500 {
+		return errors.New("o comentário pode ter no máximo 500 caracteres")
+	}
+
+	return nil
+}
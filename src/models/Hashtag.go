@@ -0,0 +1,77 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+//MaxHashtagLength é o tamanho máximo de uma hashtag, em caracteres; o que passar disso é ignorado
+const MaxHashtagLength = 100
+
+//HashtagCount é uma hashtag e o número de publicações que a usaram na janela dos trending topics
+type HashtagCount struct {
+	Tag   string `json:"tag"`
+	Posts uint64 `json:"posts"`
+}
+
+//ExtractHashtags encontra as hashtags do texto, já normalizadas e sem repetições, na ordem em que aparecem.
+//Uma hashtag começa com # no início do texto ou depois de um caractere que não faz parte de palavras.
+func ExtractHashtags(text string) []string {
+	var tags []string
+	seen := map[string]bool{}
+
+	var previous rune
+	for i, r := range text {
+		if r == '#' && !isHashtagRune(previous) {
+			end := i + 1
+			for end < len(text) {
+				next, size := utf8.DecodeRuneInString(text[end:])
+				if !isHashtagRune(next) {
+					break
+				}
+				end += size
+			}
+
+			tag := NormalizeHashtag(text[i+1 : end])
+			if tag != "" && utf8.RuneCountInString(tag) <= MaxHashtagLength && !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		previous = r
+	}
+
+	return tags
+}
+
+//NormalizeHashtag tira o # do começo, compõe os acentos (NFC) e aplica o case folding simples do Unicode, para
+//que #Go, #GO e #go sejam a mesma hashtag em qualquer alfabeto e #café digitado com o acento separado seja igual
+//ao digitado com o é pronto. Retorna vazio se o texto não é uma hashtag válida.
+func NormalizeHashtag(tag string) string {
+	tag = norm.NFC.String(strings.TrimPrefix(strings.TrimSpace(tag), "#"))
+
+	hasLetterOrDigit := false
+	for _, r := range tag {
+		if !isHashtagRune(r) {
+			return ""
+		}
+		if r != '_' {
+			hasLetterOrDigit = true
+		}
+	}
+
+	if !hasLetterOrDigit {
+		return ""
+	}
+
+	return strings.Map(func(r rune) rune {
+		return unicode.ToLower(unicode.ToUpper(r))
+	}, tag)
+}
+
+func isHashtagRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.Mn, r) || r == '_'
+}
@@ -0,0 +1,61 @@
+package models
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExtractHashtags(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{"sem hashtags", "só texto", nil},
+		{"no começo e no meio", "#Go é bom #api", []string{"go", "api"}},
+		{"repetidas com outra caixa", "#Go #GO #go", []string{"go"}},
+		{"pontuação encerra a hashtag", "(#golang), #rest!", []string{"golang", "rest"}},
+		{"colada em palavra não conta", "email#tag e a#b", nil},
+		{"hashtag seguida de hashtag", "#um#dois", []string{"um"}},
+		{"acentos", "#Café #São_Paulo", []string{"café", "são_paulo"}},
+		{"acento composto ou separado é a mesma hashtag", "#caf\u00e9 #cafe\u0301 #CAFE\u0301", []string{"caf\u00e9"}},
+		{"sigma final vira sigma comum", "#ΣΊΣΥΦΟΣ", []string{"σίσυφοσ"}},
+		{"só números", "#2024", []string{"2024"}},
+		{"só sublinhado não conta", "#_ #__", nil},
+		{"# sozinho", "# e #", nil},
+		{"longa demais é ignorada", "#" + strings.Repeat("a", MaxHashtagLength+1) + " #ok", []string{"ok"}},
+		{"no limite do tamanho", "#" + strings.Repeat("a", MaxHashtagLength), []string{strings.Repeat("a", MaxHashtagLength)}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ExtractHashtags(test.text); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ExtractHashtags(%q) = %q, esperado %q", test.text, got, test.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeHashtag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"#Go", "go"},
+		{"#Cafe\u0301", "caf\u00e9"},
+		{"#E\u0301", "\u00e9"},
+		{"  #API ", "api"},
+		{"semcerquilha", "semcerquilha"},
+		{"#com espaço", ""},
+		{"#hífen-não", ""},
+		{"#_", ""},
+		{"", ""},
+	}
+
+	for _, test := range tests {
+		if got := NormalizeHashtag(test.tag); got != test.want {
+			t.Errorf("NormalizeHashtag(%q) = %q, esperado %q", test.tag, got, test.want)
+		}
+	}
+}
@@ -0,0 +1,5 @@
+package models
+
+type MagicLinkRequest struct {
+	Email string `json:"email"`
+}
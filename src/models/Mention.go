@@ -0,0 +1,63 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//campos da publicação onde uma menção pode aparecer
+const (
+	MentionFieldTitle   = "title"
+	MentionFieldContent = "content"
+)
+
+//Mention é um @nick resolvido para um usuário. Offset e Length são contados em caracteres (code points)
+//dentro do campo indicado por Field e incluem o @.
+type Mention struct {
+	UserID uint64 `json:"user_id"`
+	Nick   string `json:"nick"`
+	Field  string `json:"field"`
+	Offset int    `json:"offset"`
+	Length int    `json:"length"`
+}
+
+//ExtractMentions encontra os @nick do texto de um campo, ainda sem o usuário resolvido. Um @ colado em uma
+//palavra, como em um email, não é uma menção.
+func ExtractMentions(field, text string) []Mention {
+	var mentions []Mention
+
+	var previous rune
+	position := 0
+	for i, r := range text {
+		if r == '@' && !isNickRune(previous) {
+			end := i + 1
+			for end < len(text) {
+				next, size := utf8.DecodeRuneInString(text[end:])
+				if !isNickRune(next) {
+					break
+				}
+				end += size
+			}
+
+			//pontuação no fim da frase não faz parte do nick
+			nick := strings.TrimRight(text[i+1:end], ".-")
+			if nick != "" {
+				mentions = append(mentions, Mention{
+					Nick:   nick,
+					Field:  field,
+					Offset: position,
+					Length: utf8.RuneCountInString(nick) + 1,
+				})
+			}
+		}
+		previous = r
+		position++
+	}
+
+	return mentions
+}
+
+func isNickRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_' || r == '.' || r == '-'
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExtractMentions(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Mention
+	}{
+		{"sem menções", "só texto", nil},
+		{
+			"no começo",
+			"@ana oi",
+			[]Mention{{Nick: "ana", Field: MentionFieldContent, Offset: 0, Length: 4}},
+		},
+		{
+			"offset em caracteres, não em bytes",
+			"olá @joão e @maria_1",
+			[]Mention{
+				{Nick: "joão", Field: MentionFieldContent, Offset: 4, Length: 5},
+				{Nick: "maria_1", Field: MentionFieldContent, Offset: 12, Length: 8},
+			},
+		},
+		{
+			"pontuação no fim da frase",
+			"valeu @ana. até @bia-",
+			[]Mention{
+				{Nick: "ana", Field: MentionFieldContent, Offset: 6, Length: 4},
+				{Nick: "bia", Field: MentionFieldContent, Offset: 16, Length: 4},
+			},
+		},
+		{
+			"ponto e hífen no meio do nick",
+			"(@dev.book-api)",
+			[]Mention{{Nick: "dev.book-api", Field: MentionFieldContent, Offset: 1, Length: 13}},
+		},
+		{"email não é menção", "escreva para ana@exemplo.com", nil},
+		{"@ sozinho", "@ e @.", nil},
+		{
+			"repetidas continuam na lista",
+			"@ana @ana",
+			[]Mention{
+				{Nick: "ana", Field: MentionFieldContent, Offset: 0, Length: 4},
+				{Nick: "ana", Field: MentionFieldContent, Offset: 5, Length: 4},
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := ExtractMentions(MentionFieldContent, test.text); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("ExtractMentions(%q) = %+v, esperado %+v", test.text, got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,18 @@
+package models
+
+import "time"
+
+//tipos de notificação
+const (
+	NotificationMention = "mention"
+)
+
+type Notification struct {
+	ID        uint64     `json:"id,omitempty"`
+	Type      string     `json:"type"`
+	ActorID   uint64     `json:"actor_id"`
+	ActorNick string     `json:"actor_nick"`
+	PostID    uint64     `json:"post_id,omitempty"`
+	ReadAt    *time.Time `json:"read_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
@@ -0,0 +1,118 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//OAuthClient é um aplicativo de terceiros que pode agir em nome dos usuários que autorizarem
+type OAuthClient struct {
+	ID           string    `json:"client_id,omitempty"`
+	Secret       string    `json:"client_secret,omitempty"`
+	SecretHash   string    `json:"-"`
+	Name         string    `json:"name,omitempty"`
+	RedirectURIs []string  `json:"redirect_uris"`
+	Confidential bool      `json:"confidential"`
+	OwnerID      uint64    `json:"owner_id,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+}
+
+func (c *OAuthClient) Prepare() error {
+	c.Name = strings.TrimSpace(c.Name)
+
+	if c.Name == "" {
+		return errors.New("o nome do aplicativo é obrigatório")
+	}
+
+	if len(c.RedirectURIs) == 0 {
+		return errors.New("informe pelo menos uma redirect_uri")
+	}
+
+	for _, redirectURI := range c.RedirectURIs {
+		parsed, err := url.Parse(redirectURI)
+		if err != nil || parsed.Scheme == "" || parsed.Host == "" || parsed.Fragment != "" {
+			return fmt.Errorf("redirect_uri inválida %s", redirectURI)
+		}
+
+		if parsed.Scheme != "https" && parsed.Hostname() != "localhost" && parsed.Hostname() != "127.0.0.1" {
+			return fmt.Errorf("a redirect_uri %s precisa usar https", redirectURI)
+		}
+	}
+
+	return nil
+}
+
+//HasRedirectURI confere a redirect_uri recebida com as cadastradas, sem nenhuma normalização
+func (c OAuthClient) HasRedirectURI(redirectURI string) bool {
+	for _, registered := range c.RedirectURIs {
+		if registered == redirectURI {
+			return true
+		}
+	}
+
+	return false
+}
+
+//AuthorizationRequest são os parâmetros de /oauth/authorize, na query (GET) ou no corpo (POST)
+type AuthorizationRequest struct {
+	ResponseType        string `json:"response_type"`
+	ClientID            string `json:"client_id"`
+	RedirectURI         string `json:"redirect_uri"`
+	Scope               string `json:"scope"`
+	State               string `json:"state"`
+	CodeChallenge       string `json:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method"`
+	Approve             bool   `json:"approve"`
+}
+
+type AuthorizationCode struct {
+	ID                  uint64
+	UserID              uint64
+	ClientID            string
+	CodeHash            string
+	RedirectURI         string
+	Scopes              []string
+	CodeChallenge       string
+	CodeChallengeMethod string
+	FamilyID            string
+	ExpiresAt           time.Time
+	UsedAt              *time.Time
+}
+
+//OAuthGrant é um aplicativo que o usuário autorizou e que ainda pode renovar os tokens em nome dele
+type OAuthGrant struct {
+	ClientID   string    `json:"client_id"`
+	ClientName string    `json:"client_name"`
+	Scopes     []string  `json:"scopes"`
+	LastUsedAt time.Time `json:"last_used_at"`
+}
+
+//OAuthConsent é o que o front-end mostra ao usuário antes de ele autorizar o aplicativo
+type OAuthConsent struct {
+	Client      OAuthClient `json:"client"`
+	Scopes      []string    `json:"scopes"`
+	RedirectURI string      `json:"redirect_uri"`
+	State       string      `json:"state,omitempty"`
+}
+
+type OAuthRedirect struct {
+	RedirectTo string `json:"redirect_to"`
+}
+
+//OAuthToken é a resposta de /oauth/token no formato do RFC 6749
+type OAuthToken struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	Scope        string `json:"scope"`
+}
+
+//OAuthError segue o formato de erro do RFC 6749, que os clientes OAuth esperam
+type OAuthError struct {
+	Error       string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
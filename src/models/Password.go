@@ -3,4 +3,13 @@ package models
 type UpdatePassword struct {
 	NewPassword string `json:"new_password"`
 	OldPassword string `json:"old_password"`
-}
\ No newline at end of file
+}
+
+type ForgotPassword struct {
+	Email string `json:"email"`
+}
+
+type ResetPassword struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+type PersonalAccessToken struct {
+	ID         uint64     `json:"id,omitempty"`
+	UserID     uint64     `json:"user_id,omitempty"`
+	Name       string     `json:"name,omitempty"`
+	Token      string     `json:"token,omitempty"`
+	Prefix     string     `json:"prefix,omitempty"`
+	TokenHash  string     `json:"token_hash,omitempty"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+}
+
+func (t *PersonalAccessToken) Prepare() error {
+	t.Name = strings.TrimSpace(t.Name)
+
+	if t.Name == "" {
+		return errors.New("o nome do token é obrigatório")
+	}
+
+	if err := ValidateScopes(t.Scopes); err != nil {
+		return err
+	}
+
+	if t.ExpiresAt != nil && t.ExpiresAt.Before(time.Now()) {
+		return errors.New("a data de expiração precisa estar no futuro")
+	}
+
+	return nil
+}
@@ -2,18 +2,100 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"strings"
 	"time"
 )
 
+//tipos de publicação: uma publicação normal, um repost simples ou uma citação, que compartilha com um texto próprio
+const (
+	PostKindPost   = "post"
+	PostKindRepost = "repost"
+	PostKindQuote  = "quote"
+)
+
+//situações de uma publicação: só a publicada aparece para os outros usuários; a agendada é publicada em PublishAt
+const (
+	PostStatusDraft     = "draft"
+	PostStatusScheduled = "scheduled"
+	PostStatusPublished = "published"
+)
+
 type Post struct {
-	ID         uint64    `json:"id,omitempty"`
-	Title      string    `json:"title,omitempty"`
-	Content    string    `json:"content,omitempty"`
-	AuthorID   uint64    `json:"author_id,omitempty"`
-	AuthorNick string    `json:"author_nick,omitempty"`
-	Likes      uint64    `json:"likes"`
-	CreatedAt  time.Time `json:"created_at,omitempty"`
+	ID           uint64    `json:"id,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Content      string    `json:"content,omitempty"`
+	AuthorID     uint64    `json:"author_id,omitempty"`
+	AuthorNick   string    `json:"author_nick,omitempty"`
+	AuthorAvatar ImageURLs `json:"author_avatar,omitempty"`
+	Likes        uint64    `json:"likes"`
+	LikedByMe    bool      `json:"liked_by_me"`
+	Comments     uint64    `json:"comments"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+
+	AuthorAvatarKey string `json:"-"`
+
+	BookmarkedByMe bool `json:"bookmarked_by_me"`
+
+	Status    string     `json:"status,omitempty"`
+	PublishAt *time.Time `json:"publish_at,omitempty"`
+
+	EditedAt      *time.Time `json:"edited_at,omitempty"`
+	RevisionCount uint64     `json:"revision_count"`
+
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	DeletedBy uint64     `json:"-"`
+
+	Attachments []Attachment `json:"attachments"`
+
+	Hashtags []string      `json:"hashtags,omitempty"`
+	Mentions []Mention     `json:"mentions,omitempty"`
+	Kind     string        `json:"kind,omitempty"`
+	RepostOf uint64        `json:"-"`
+	Original *EmbeddedPost `json:"original,omitempty"`
+}
+
+//SetURLs monta os endereços dos avatares e dos anexos da publicação e da original com a função url do armazenamento
+func (p *Post) SetURLs(url func(key string) string) {
+	p.AuthorAvatar = NewImageURLs(p.AuthorAvatarKey, AvatarSizes, url)
+	for i := range p.Attachments {
+		p.Attachments[i].SetURLs(url)
+	}
+
+	if p.Original != nil {
+		p.Original.AuthorAvatar = NewImageURLs(p.Original.AuthorAvatarKey, AvatarSizes, url)
+		for i := range p.Original.Attachments {
+			p.Original.Attachments[i].SetURLs(url)
+		}
+	}
+}
+
+//BookmarkPage é uma página das publicações salvas; NextCursor vai no parâmetro cursor para buscar a próxima
+type BookmarkPage struct {
+	Posts      []Post `json:"posts"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+//EmbeddedPost é a publicação original mostrada dentro de um repost ou de uma citação
+type EmbeddedPost struct {
+	ID           uint64    `json:"id,omitempty"`
+	Title        string    `json:"title,omitempty"`
+	Content      string    `json:"content,omitempty"`
+	AuthorID     uint64    `json:"author_id,omitempty"`
+	AuthorNick   string    `json:"author_nick,omitempty"`
+	AuthorAvatar ImageURLs `json:"author_avatar,omitempty"`
+	CreatedAt    time.Time `json:"created_at,omitempty"`
+	Unavailable  bool      `json:"unavailable,omitempty"`
+	Message      string    `json:"message,omitempty"`
+
+	AuthorAvatarKey string `json:"-"`
+
+	Attachments []Attachment `json:"attachments,omitempty"`
+}
+
+//UnavailablePost é o que as citações mostram no lugar de uma publicação original que foi apagada
+func UnavailablePost() EmbeddedPost {
+	return EmbeddedPost{Unavailable: true, Message: "publicação indisponível"}
 }
 
 func (post *Post) Prepare() error {
@@ -26,7 +108,8 @@ func (post *Post) Prepare() error {
 }
 
 func (post *Post) validate() error {
-	if post.Title == "" {
+	//a citação pode ter só o texto, sem título
+	if post.Title == "" && post.Kind != PostKindQuote {
 		return errors.New("o título é obrigatório")
 	}
 
@@ -34,10 +117,38 @@ func (post *Post) validate() error {
 		return errors.New("a publicação precisa ter um conteudo")
 	}
 
+	return post.validateStatus()
+}
+
+//validateStatus confere o agendamento; sem situação informada a publicação sai na hora
+func (post *Post) validateStatus() error {
+	if post.Status == "" {
+		post.Status = PostStatusPublished
+	}
+
+	switch post.Status {
+	case PostStatusScheduled:
+		if post.PublishAt == nil {
+			return errors.New("informe quando a publicação agendada deve sair em publish_at")
+		}
+
+		if !post.PublishAt.After(time.Now()) {
+			return errors.New("a data de publicação precisa estar no futuro")
+		}
+	case PostStatusDraft, PostStatusPublished:
+		if post.PublishAt != nil {
+			return errors.New("publish_at só vale para publicações agendadas")
+		}
+	default:
+		return fmt.Errorf("situação de publicação desconhecida %s", post.Status)
+	}
+
 	return nil
 }
 
 func (post *Post) format(){
 	post.Title = strings.TrimSpace(post.Title)
 	post.Content = strings.TrimSpace(post.Content)
+	post.Hashtags = ExtractHashtags(post.Title + "\n" + post.Content)
+	post.Mentions = append(ExtractMentions(MentionFieldTitle, post.Title), ExtractMentions(MentionFieldContent, post.Content)...)
 }
\ No newline at end of file
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+//PostRevision é uma versão anterior de uma publicação, guardada quando ela foi editada depois de publicada.
+//As revisões são numeradas a partir de 1; a versão atual é a de número RevisionCount + 1.
+type PostRevision struct {
+	Revision   uint64    `json:"revision"`
+	Title      string    `json:"title"`
+	Content    string    `json:"content"`
+	EditorID   uint64    `json:"editor_id,omitempty"`
+	ReplacedAt time.Time `json:"replaced_at"`
+}
+
+//Text é o texto comparado no diff: o título, uma linha em branco e o conteúdo
+func (revision PostRevision) Text() string {
+	return revision.Title + "\n\n" + revision.Content
+}
+
+type RevisionDiff struct {
+	From uint64 `json:"from"`
+	To   uint64 `json:"to"`
+	Diff string `json:"diff"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+)
+
+//larguras geradas para as imagens de perfil; o avatar é quadrado e o banner tem proporção 3:1
+var (
+	AvatarSizes = []int{48, 128, 400}
+	BannerSizes = []int{600, 1000, 1500}
+)
+
+//ImageURLs são os endereços de cada versão de uma imagem de perfil, pela largura em pixels
+type ImageURLs map[string]string
+
+//NewImageURLs monta os endereços das versões da imagem com a função url do armazenamento; sem chave não há imagem
+func NewImageURLs(key string, widths []int, url func(key string) string) ImageURLs {
+	if key == "" {
+		return nil
+	}
+
+	urls := ImageURLs{}
+	for _, width := range widths {
+		urls[strconv.Itoa(width)] = url(VariantKey(key, width))
+	}
+
+	return urls
+}
+
+//VariantKey é a chave de armazenamento da versão com a largura informada: a largura entra antes da extensão,
+//então "users/1/avatar-abc.jpg" vira "users/1/avatar-abc-48.jpg"
+func VariantKey(key string, width int) string {
+	extension := ""
+	if dot := strings.LastIndex(key, "."); dot > strings.LastIndex(key, "/") {
+		key, extension = key[:dot], key[dot:]
+	}
+
+	return key + "-" + strconv.Itoa(width) + extension
+}
+
+//VariantKeys lista as chaves de todas as versões da imagem
+func VariantKeys(key string, widths []int) []string {
+	keys := make([]string, len(widths))
+	for i, width := range widths {
+		keys[i] = VariantKey(key, width)
+	}
+
+	return keys
+}
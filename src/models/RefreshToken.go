@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+type RefreshToken struct {
+	ID        uint64     `json:"id,omitempty"`
+	UserID    uint64     `json:"user_id,omitempty"`
+	FamilyID  string     `json:"family_id,omitempty"`
+	ClientID  string     `json:"client_id,omitempty"`
+	Scopes    []string   `json:"scopes,omitempty"`
+	TokenHash string     `json:"-"`
+	ExpiresAt time.Time  `json:"expires_at,omitempty"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at,omitempty"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
@@ -0,0 +1,43 @@
+package models
+
+import "time"
+
+const (
+	RoleAdmin     = "admin"
+	RoleModerator = "moderator"
+)
+
+//CanOverride indica se quem tem actorRoles pode agir sobre a conta de quem tem targetRoles: administradores
+//podem sobre qualquer conta e moderadores só sobre a de quem não é nem administrador nem moderador
+func CanOverride(actorRoles, targetRoles []string) bool {
+	if hasRole(actorRoles, RoleAdmin) {
+		return true
+	}
+
+	if !hasRole(actorRoles, RoleModerator) {
+		return false
+	}
+
+	return !hasRole(targetRoles, RoleAdmin) && !hasRole(targetRoles, RoleModerator)
+}
+
+func hasRole(roles []string, role string) bool {
+	for _, r := range roles {
+		if r == role {
+			return true
+		}
+	}
+
+	return false
+}
+
+//AuditLog registra uma ação feita por um administrador ou moderador sobre um recurso de outro usuário
+type AuditLog struct {
+	ID         uint64    `json:"id,omitempty"`
+	ActorID    uint64    `json:"actor_id,omitempty"`
+	ActorNick  string    `json:"actor_nick,omitempty"`
+	Action     string    `json:"action,omitempty"`
+	TargetType string    `json:"target_type,omitempty"`
+	TargetID   uint64    `json:"target_id,omitempty"`
+	CreatedAt  time.Time `json:"created_at,omitempty"`
+}
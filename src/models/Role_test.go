@@ -0,0 +1,36 @@
+package models
+
+import "testing"
+
+func TestCanOverride(t *testing.T) {
+	admin := []string{RoleAdmin}
+	moderator := []string{RoleModerator}
+	both := []string{RoleModerator, RoleAdmin}
+	user := []string{}
+
+	tests := []struct {
+		name   string
+		actor  []string
+		target []string
+		want   bool
+	}{
+		{"administrador sobre usuário", admin, user, true},
+		{"administrador sobre moderador", admin, moderator, true},
+		{"administrador sobre administrador", admin, admin, true},
+		{"moderador sobre usuário", moderator, user, true},
+		{"moderador sobre moderador", moderator, moderator, false},
+		{"moderador sobre administrador", moderator, admin, false},
+		{"moderador sobre quem tem os dois papéis", moderator, both, false},
+		{"quem tem os dois papéis age como administrador", both, admin, true},
+		{"usuário sobre usuário", user, user, false},
+		{"papel desconhecido", []string{"editor"}, user, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := CanOverride(test.actor, test.target); got != test.want {
+				t.Errorf("CanOverride(%v, %v) = %v, esperado %v", test.actor, test.target, got, test.want)
+			}
+		})
+	}
+}
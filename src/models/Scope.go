@@ -0,0 +1,40 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+)
+
+//escopos que limitam o que um token de script ou de aplicativo de terceiros pode fazer
+const (
+	ScopePostsRead  = "posts:read"
+	ScopePostsWrite = "posts:write"
+	ScopeUsersRead  = "users:read"
+	ScopeUsersWrite = "users:write"
+)
+
+var Scopes = []string{ScopePostsRead, ScopePostsWrite, ScopeUsersRead, ScopeUsersWrite}
+
+func ValidateScopes(scopes []string) error {
+	if len(scopes) == 0 {
+		return errors.New("informe pelo menos um escopo")
+	}
+
+	for _, scope := range scopes {
+		if !isKnownScope(scope) {
+			return fmt.Errorf("escopo desconhecido %s", scope)
+		}
+	}
+
+	return nil
+}
+
+func isKnownScope(scope string) bool {
+	for _, known := range Scopes {
+		if scope == known {
+			return true
+		}
+	}
+
+	return false
+}
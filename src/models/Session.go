@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+//Session é um login feito em um dispositivo; dura enquanto a família de refresh tokens dele for renovada
+type Session struct {
+	ID         uint64     `json:"id,omitempty"`
+	UserID     uint64     `json:"-"`
+	FamilyID   string     `json:"-"`
+	UserAgent  string     `json:"user_agent"`
+	IP         string     `json:"ip"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	LastSeenAt time.Time  `json:"last_seen_at,omitempty"`
+	RevokedAt  *time.Time `json:"-"`
+	Current    bool       `json:"current"`
+}
@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+type SigningKey struct {
+	KID        string     `json:"kid,omitempty"`
+	Algorithm  string     `json:"algorithm,omitempty"`
+	PrivateKey string     `json:"-"`
+	PublicKey  string     `json:"public_key,omitempty"`
+	CreatedAt  time.Time  `json:"created_at,omitempty"`
+	SignsAt    time.Time  `json:"signs_at,omitempty"`
+	RetiresAt  *time.Time `json:"retires_at,omitempty"`
+}
+
+//JWK é a representação pública de uma chave de assinatura (RFC 7517)
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+	X         string `json:"x,omitempty"`
+	Y         string `json:"y,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
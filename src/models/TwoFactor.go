@@ -0,0 +1,33 @@
+package models
+
+type TwoFactorEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"otpauth_uri"`
+}
+
+type TwoFactorCode struct {
+	Code     string `json:"code"`
+	Password string `json:"password,omitempty"`
+}
+
+type TwoFactorLogin struct {
+	ChallengeToken string `json:"challenge_token"`
+	Code           string `json:"code"`
+}
+
+//TwoFactorChallenge é a resposta do login quando o usuário precisa informar o segundo fator
+type TwoFactorChallenge struct {
+	TwoFactorRequired bool   `json:"two_factor_required"`
+	ChallengeToken    string `json:"challenge_token"`
+}
+
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
+
+//TwoFactorSettings é o estado do 2FA de um usuário como está salvo no banco
+type TwoFactorSettings struct {
+	Secret   string
+	Enabled  bool
+	LastStep int64
+}
@@ -16,6 +16,22 @@ type User struct {
 	Email     string    `json:"email,omitempty"`
 	Password  string    `json:"password,omitempty"`
 	CreatedAt time.Time `json:"created_at,omitempty"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+
+	//DeletedBy é quem mandou a conta para a lixeira: ela mesma ou alguém da moderação
+	DeletedBy uint64 `json:"-"`
+
+	AvatarKey string    `json:"-"`
+	BannerKey string    `json:"-"`
+	Avatar    ImageURLs `json:"avatar,omitempty"`
+	Banner    ImageURLs `json:"banner,omitempty"`
+}
+
+//SetURLs monta os endereços das versões do avatar e do banner a partir das chaves guardadas
+func (u *User) SetURLs(url func(key string) string) {
+	u.Avatar = NewImageURLs(u.AvatarKey, AvatarSizes, url)
+	u.Banner = NewImageURLs(u.BannerKey, BannerSizes, url)
 }
 
 func (u *User) Prepare(stage string) error {
@@ -45,8 +61,14 @@ func (u *User) validate(stage string) error {
 		return errors.New("o email inserido é inválido")
 	}
 
-	if stage == "register" && u.Password == "" {
-		return errors.New("o campo senha é obrigatório")
+	if stage == "register" {
+		if u.Password == "" {
+			return errors.New("o campo senha é obrigatório")
+		}
+
+		if err := security.CheckPassword("password", u.Password, u.Nick, u.Email); err != nil {
+			return err
+		}
 	}
 
 	return nil
@@ -0,0 +1,167 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type Attachments struct {
+	db *sql.DB
+}
+
+func NewAttachmentRep(db *sql.DB) *Attachments {
+	return &Attachments{db}
+}
+
+//Create grava o anexo se a publicação ainda tiver menos de max anexos; retorna 0 se ela já estiver cheia.
+//A linha da publicação fica travada entre a contagem e o insert, então envios simultâneos não passam do limite.
+func (a Attachments) Create(attachment models.Attachment, max int) (uint64, error) {
+	tx, err := a.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("select id from posts where id = ? for update", attachment.PostID); err != nil {
+		return 0, err
+	}
+
+	var count int
+	if err = tx.QueryRow("select count(*) from attachments where post_id = ?", attachment.PostID).Scan(&count); err != nil {
+		return 0, err
+	}
+
+	if count >= max {
+		return 0, nil
+	}
+
+	result, err := tx.Exec(
+		"insert into attachments (post_id, storage_key, thumbnail_key, content_type, size, width, height) values(?,?,?,?,?,?,?)",
+		attachment.PostID, attachment.Key, attachment.ThumbnailKey, attachment.ContentType, attachment.Size, attachment.Width, attachment.Height,
+	)
+	if err != nil {
+		return 0, err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastID), tx.Commit()
+}
+
+//GetByID retorna o anexo da publicação, ou um anexo vazio se ele não existir
+func (a Attachments) GetByID(postID, attachmentID uint64) (models.Attachment, error) {
+	attachments, err := a.search("where post_id = ? and id = ?", postID, attachmentID)
+	if err != nil || len(attachments) == 0 {
+		return models.Attachment{}, err
+	}
+
+	return attachments[0], nil
+}
+
+func (a Attachments) Delete(attachmentID uint64) error {
+	_, err := a.db.Exec("delete from attachments where id = ?", attachmentID)
+	return err
+}
+
+//SearchPurgeable lista os anexos das publicações e dos usuários que a limpeza da lixeira vai apagar,
+//para que os arquivos sejam removidos do armazenamento antes das linhas sumirem
+func (a Attachments) SearchPurgeable(before time.Time) ([]models.Attachment, error) {
+	return a.search(
+		"where post_id in (select p.id from posts p inner join users u on u.id = p.author_id where p.deleted_at < ? or u.deleted_at < ?)",
+		before, before,
+	)
+}
+
+func (a Attachments) search(where string, args ...interface{}) ([]models.Attachment, error) {
+	rows, err := a.db.Query(
+		"select id, post_id, storage_key, thumbnail_key, content_type, size, width, height, created_at from attachments "+where+" order by id",
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var attachments []models.Attachment
+
+	for rows.Next() {
+		attachment, err := scanAttachment(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		attachments = append(attachments, attachment)
+	}
+
+	return attachments, rows.Err()
+}
+
+func scanAttachment(rows *sql.Rows) (models.Attachment, error) {
+	var attachment models.Attachment
+
+	if err := rows.Scan(
+		&attachment.ID,
+		&attachment.PostID,
+		&attachment.Key,
+		&attachment.ThumbnailKey,
+		&attachment.ContentType,
+		&attachment.Size,
+		&attachment.Width,
+		&attachment.Height,
+		&attachment.CreatedAt,
+	); err != nil {
+		return models.Attachment{}, err
+	}
+
+	return attachment, nil
+}
+
+//loadAttachments preenche com uma única consulta os anexos das publicações e das originais que elas compartilham
+func (p Posts) loadAttachments(posts []models.Post) error {
+	for i := range posts {
+		posts[i].Attachments = []models.Attachment{}
+	}
+
+	var placeholders []string
+	var args []interface{}
+	for _, post := range posts {
+		placeholders = append(placeholders, "?")
+		args = append(args, post.ID)
+
+		if post.Original != nil && post.Original.ID != 0 {
+			placeholders = append(placeholders, "?")
+			args = append(args, post.Original.ID)
+		}
+	}
+
+	if len(args) == 0 {
+		return nil
+	}
+
+	found, err := Attachments{p.db}.search("where post_id in ("+strings.Join(placeholders, ",")+")", args...)
+	if err != nil {
+		return err
+	}
+
+	byPost := map[uint64][]models.Attachment{}
+	for _, attachment := range found {
+		byPost[attachment.PostID] = append(byPost[attachment.PostID], attachment)
+	}
+
+	for i := range posts {
+		if attachments, ok := byPost[posts[i].ID]; ok {
+			posts[i].Attachments = attachments
+		}
+
+		if posts[i].Original != nil && posts[i].Original.ID != 0 {
+			posts[i].Original.Attachments = byPost[posts[i].Original.ID]
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+type AuditLogs struct {
+	db *sql.DB
+}
+
+func NewAuditLogRep(db *sql.DB) *AuditLogs {
+	return &AuditLogs{db}
+}
+
+func (a AuditLogs) Create(log models.AuditLog) error {
+	sql, err := a.db.Prepare("insert into audit_logs (actor_id, action, target_type, target_id) values(?,?,?,?)")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(log.ActorID, log.Action, log.TargetType, log.TargetID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (a AuditLogs) Search(limit int) ([]models.AuditLog, error) {
+	sql, err := a.db.Query(
+		"select a.id, coalesce(a.actor_id, 0), coalesce(u.nick, ''), a.action, a.target_type, a.target_id, a.created_at from audit_logs a left join users u on u.id = a.actor_id order by a.id desc limit ?",
+		limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer sql.Close()
+
+	var logs []models.AuditLog
+
+	for sql.Next() {
+		var log models.AuditLog
+		if err = sql.Scan(
+			&log.ID,
+			&log.ActorID,
+			&log.ActorNick,
+			&log.Action,
+			&log.TargetType,
+			&log.TargetID,
+			&log.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		logs = append(logs, log)
+	}
+
+	return logs, nil
+}
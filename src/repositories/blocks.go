@@ -0,0 +1,22 @@
+package repositories
+
+import "database/sql"
+
+type Blocks struct {
+	db *sql.DB
+}
+
+func NewBlockRep(db *sql.DB) *Blocks {
+	return &Blocks{db}
+}
+
+//Block impede que blockedID interaja com blockerID; bloquear de novo não muda nada
+func (b Blocks) Block(blockerID, blockedID uint64) error {
+	_, err := b.db.Exec("insert ignore into user_blocks (blocker_id, blocked_id) values(?,?)", blockerID, blockedID)
+	return err
+}
+
+func (b Blocks) Unblock(blockerID, blockedID uint64) error {
+	_, err := b.db.Exec("delete from user_blocks where blocker_id = ? and blocked_id = ?", blockerID, blockedID)
+	return err
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+)
+
+type Bookmarks struct {
+	db *sql.DB
+}
+
+func NewBookmarkRep(db *sql.DB) *Bookmarks {
+	return &Bookmarks{db}
+}
+
+//Add salva a publicação para o usuário; salvar de novo não muda nada
+func (b Bookmarks) Add(userID, postID uint64) error {
+	_, err := b.db.Exec("insert ignore into bookmarks (user_id, post_id) values(?,?)", userID, postID)
+	return err
+}
+
+func (b Bookmarks) Remove(userID, postID uint64) error {
+	_, err := b.db.Exec("delete from bookmarks where user_id = ? and post_id = ?", userID, postID)
+	return err
+}
+
+//SearchBookmarks lista as publicações salvas pelo usuário, das salvas por último para as mais antigas.
+//before é o cursor da página (0 para a primeira) e o segundo retorno é o cursor da próxima, ou 0 se não houver mais.
+func (p Posts) SearchBookmarks(userID, before uint64, limit int) ([]models.Post, uint64, error) {
+	query := "select id, post_id from bookmarks where user_id = ? order by id desc limit ?"
+	args := []interface{}{userID, limit + 1}
+	if before != 0 {
+		query = "select id, post_id from bookmarks where user_id = ? and id < ? order by id desc limit ?"
+		args = []interface{}{userID, before, limit + 1}
+	}
+
+	rows, err := p.db.Query(query, args...)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer rows.Close()
+
+	var bookmarkIDs []uint64
+	var postIDs []interface{}
+
+	for rows.Next() {
+		var bookmarkID, postID uint64
+		if err = rows.Scan(&bookmarkID, &postID); err != nil {
+			return nil, 0, err
+		}
+
+		bookmarkIDs = append(bookmarkIDs, bookmarkID)
+		postIDs = append(postIDs, postID)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, 0, err
+	}
+
+	var next uint64
+	if len(bookmarkIDs) > limit {
+		bookmarkIDs, postIDs = bookmarkIDs[:limit], postIDs[:limit]
+		next = bookmarkIDs[limit-1]
+	}
+
+	if len(postIDs) == 0 {
+		return nil, 0, nil
+	}
+
+	placeholders := strings.TrimSuffix(strings.Repeat("?,", len(postIDs)), ",")
+	found, err := p.search("p.id in ("+placeholders+")", userID, postIDs...)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	//a consulta não garante a ordem, então as publicações voltam para a ordem em que foram salvas
+	byID := make(map[uint64]models.Post, len(found))
+	for _, post := range found {
+		byID[post.ID] = post
+	}
+
+	posts := make([]models.Post, 0, len(found))
+	for _, postID := range postIDs {
+		if post, ok := byID[postID.(uint64)]; ok {
+			posts = append(posts, post)
+		}
+	}
+
+	return posts, next, nil
+}
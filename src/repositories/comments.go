@@ -0,0 +1,134 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+type Comments struct {
+	db *sql.DB
+}
+
+func NewCommentRep(db *sql.DB) *Comments {
+	return &Comments{db}
+}
+
+func (c Comments) Create(comment models.Comment) (uint64, error) {
+	sql, err := c.db.Prepare("insert into comments (post_id, parent_id, author_id, content) values(?,?,?,?)")
+	if err != nil {
+		return 0, err
+	}
+	defer sql.Close()
+
+	result, err := sql.Exec(comment.PostID, comment.ParentID, comment.AuthorID, comment.Content)
+	if err != nil {
+		return 0, err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastID), nil
+}
+
+//GetByID retorna um comentário vazio se ele não existe ou não é da publicação informada
+func (c Comments) GetByID(postID, commentID uint64) (models.Comment, error) {
+	comments, err := c.search("where c.post_id = ? and c.id = ?", postID, commentID)
+	if err != nil || len(comments) == 0 {
+		return models.Comment{}, err
+	}
+
+	return comments[0], nil
+}
+
+//Search lista uma página dos comentários da publicação. Sem parentID são listados os comentários de primeiro
+//nível; com ele, as respostas daquele comentário.
+func (c Comments) Search(postID uint64, parentID *uint64, newestFirst bool, limit, offset int) ([]models.Comment, error) {
+	order := "asc"
+	if newestFirst {
+		order = "desc"
+	}
+
+	if parentID == nil {
+		return c.search("where c.post_id = ? and c.parent_id is null order by c.id "+order+" limit ? offset ?", postID, limit, offset)
+	}
+
+	return c.search("where c.post_id = ? and c.parent_id = ? order by c.id "+order+" limit ? offset ?", postID, *parentID, limit, offset)
+}
+
+func (c Comments) Update(commentID uint64, content string) error {
+	sql, err := c.db.Prepare("update comments set content = ?, updated_at = current_timestamp where id = ?")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(content, commentID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//Delete apaga o comentário junto com as respostas a ele
+func (c Comments) Delete(commentID uint64) error {
+	sql, err := c.db.Prepare("delete from comments where id = ?")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(commentID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (c Comments) search(where string, args ...interface{}) ([]models.Comment, error) {
+	rows, err := c.db.Query(
+		"select c.id, c.post_id, c.parent_id, c.author_id, u.nick, c.content, "+
+			"(select count(*) from comments r inner join users ru on ru.id = r.author_id and ru.deleted_at is null where r.parent_id = c.id), "+
+			"c.created_at, c.updated_at from comments c inner join users u on u.id = c.author_id and u.deleted_at is null "+where,
+		args...,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var comments []models.Comment
+
+	for rows.Next() {
+		var comment models.Comment
+		var parentID sql.NullInt64
+		var updatedAt sql.NullTime
+
+		if err = rows.Scan(
+			&comment.ID,
+			&comment.PostID,
+			&parentID,
+			&comment.AuthorID,
+			&comment.AuthorNick,
+			&comment.Content,
+			&comment.Replies,
+			&comment.CreatedAt,
+			&updatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if parentID.Valid {
+			id := uint64(parentID.Int64)
+			comment.ParentID = &id
+		}
+		if updatedAt.Valid {
+			comment.UpdatedAt = &updatedAt.Time
+		}
+		comments = append(comments, comment)
+	}
+
+	return comments, nil
+}
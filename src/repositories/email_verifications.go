@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+type EmailVerifications struct {
+	db *sql.DB
+}
+
+func NewEmailVerificationRep(db *sql.DB) *EmailVerifications {
+	return &EmailVerifications{db}
+}
+
+//Create registra um token para o email informado e invalida os tokens enviados antes para o mesmo usuário
+func (e EmailVerifications) Create(userID uint64, email, tokenHash string, expiresAt time.Time) error {
+	if _, err := e.db.Exec("update email_verifications set used_at = current_timestamp where user_id = ? and used_at is null", userID); err != nil {
+		return err
+	}
+
+	sql, err := e.db.Prepare("insert into email_verifications (user_id, email, token_hash, expires_at) values(?,?,?,?)")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(userID, email, tokenHash, expiresAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//Consume marca o token como usado e retorna o usuário e o email que ele confirma; retorna 0 se o token não vale mais
+func (e EmailVerifications) Consume(tokenHash string) (uint64, string, error) {
+	result, err := e.db.Exec("update email_verifications set used_at = current_timestamp where token_hash = ? and used_at is null and expires_at > ?", tokenHash, time.Now())
+	if err != nil {
+		return 0, "", err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, "", err
+	}
+
+	sql, err := e.db.Query("select user_id, email from email_verifications where token_hash = ?", tokenHash)
+	if err != nil {
+		return 0, "", err
+	}
+	defer sql.Close()
+
+	var userID uint64
+	var email string
+	if sql.Next() {
+		if err = sql.Scan(&userID, &email); err != nil {
+			return 0, "", err
+		}
+	}
+
+	return userID, email, nil
+}
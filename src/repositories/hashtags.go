@@ -0,0 +1,53 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+type Hashtags struct {
+	db *sql.DB
+}
+
+func NewHashtagRep(db *sql.DB) *Hashtags {
+	return &Hashtags{db}
+}
+
+//Trending conta em quantas publicações feitas desde since cada hashtag apareceu, das mais usadas para as menos usadas.
+//Vale a data da publicação, então editar uma publicação antiga não a traz de volta para os trending topics.
+func (h Hashtags) Trending(since time.Time, limit int) ([]models.HashtagCount, error) {
+	rows, err := h.db.Query(
+		"select h.tag, count(*) as total from post_hashtags h inner join posts p on p.id = h.post_id inner join users u on u.id = p.author_id "+
+			"where p.status = 'published' and p.deleted_at is null and u.deleted_at is null and p.created_at > ? group by h.tag order by total desc, h.tag limit ?",
+		since, limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trending []models.HashtagCount
+
+	for rows.Next() {
+		var hashtag models.HashtagCount
+		if err = rows.Scan(&hashtag.Tag, &hashtag.Posts); err != nil {
+			return nil, err
+		}
+
+		trending = append(trending, hashtag)
+	}
+
+	return trending, nil
+}
+
+//indexHashtags grava as hashtags da publicação; é chamado dentro da transação que cria ou altera a publicação
+func indexHashtags(tx *sql.Tx, postID uint64, tags []string) error {
+	for _, tag := range tags {
+		if _, err := tx.Exec("insert into post_hashtags (post_id, tag) values(?,?)", postID, tag); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import "database/sql"
+
+//Locks usa os named locks do MySQL para coordenar tarefas entre várias réplicas da API.
+//Os locks pertencem à conexão, então o *sql.DB usado aqui deve ter no máximo uma conexão aberta.
+type Locks struct {
+	db *sql.DB
+}
+
+func NewLockRep(db *sql.DB) *Locks {
+	db.SetMaxOpenConns(1)
+	return &Locks{db}
+}
+
+//Acquire tenta pegar o lock sem esperar; retorna false se outra réplica já estiver com ele
+func (l Locks) Acquire(name string) (bool, error) {
+	rows, err := l.db.Query("select coalesce(get_lock(?, 0), 0)", name)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var acquired bool
+	if rows.Next() {
+		if err = rows.Scan(&acquired); err != nil {
+			return false, err
+		}
+	}
+
+	return acquired, nil
+}
+
+func (l Locks) Release(name string) error {
+	_, err := l.db.Exec("select release_lock(?)", name)
+	return err
+}
@@ -0,0 +1,96 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+type LoginAttempts struct {
+	db *sql.DB
+}
+
+func NewLoginAttemptRep(db *sql.DB) *LoginAttempts {
+	return &LoginAttempts{db}
+}
+
+//Reserve conta uma tentativa para a chave travando a linha, para que requisições simultâneas não passem todas
+//pela mesma checagem. Se a chave ainda está bloqueada nada é contado e o retorno é o fim do bloqueio; senão a
+//tentativa já conta como falha, com o bloqueio calculado por delay, e o retorno é zero.
+//Falhas anteriores a windowStart são descartadas
+func (l LoginAttempts) Reserve(key string, now, windowStart time.Time, delay func(int) time.Duration) (time.Time, error) {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("insert ignore into login_attempts (attempt_key, failures, last_failure_at) values(?, 0, ?)", key, now); err != nil {
+		return time.Time{}, err
+	}
+
+	var failures int
+	var lastFailureAt time.Time
+	var blockedUntil sql.NullTime
+	if err = tx.QueryRow(
+		"select failures, last_failure_at, blocked_until from login_attempts where attempt_key = ? for update", key,
+	).Scan(&failures, &lastFailureAt, &blockedUntil); err != nil {
+		return time.Time{}, err
+	}
+
+	if blockedUntil.Valid && blockedUntil.Time.After(now) {
+		return blockedUntil.Time, tx.Commit()
+	}
+
+	if lastFailureAt.Before(windowStart) {
+		failures = 0
+	}
+	failures++
+
+	if _, err = tx.Exec(
+		"update login_attempts set failures = ?, last_failure_at = ?, blocked_until = ? where attempt_key = ?",
+		failures, now, now.Add(delay(failures)), key,
+	); err != nil {
+		return time.Time{}, err
+	}
+
+	return time.Time{}, tx.Commit()
+}
+
+//Release devolve uma tentativa reservada que não chegou a falhar, recalculando o bloqueio com as falhas que sobraram
+func (l LoginAttempts) Release(key string, delay func(int) time.Duration) error {
+	tx, err := l.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var failures int
+	var lastFailureAt time.Time
+	err = tx.QueryRow("select failures, last_failure_at from login_attempts where attempt_key = ? for update", key).Scan(&failures, &lastFailureAt)
+	if err == sql.ErrNoRows {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	failures--
+	if failures <= 0 {
+		_, err = tx.Exec("delete from login_attempts where attempt_key = ?", key)
+	} else {
+		_, err = tx.Exec(
+			"update login_attempts set failures = ?, blocked_until = ? where attempt_key = ?",
+			failures, lastFailureAt.Add(delay(failures)), key,
+		)
+	}
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (l LoginAttempts) Reset(key string) error {
+	_, err := l.db.Exec("delete from login_attempts where attempt_key = ?", key)
+	return err
+}
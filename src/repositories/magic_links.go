@@ -0,0 +1,67 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+type MagicLinks struct {
+	db *sql.DB
+}
+
+func NewMagicLinkRep(db *sql.DB) *MagicLinks {
+	return &MagicLinks{db}
+}
+
+//Create registra um link de login para o email informado e invalida os links enviados antes para o mesmo usuário.
+//A linha do usuário fica travada na transação, então dois pedidos simultâneos não deixam dois links valendo.
+func (m MagicLinks) Create(userID uint64, email, tokenHash string, expiresAt time.Time) error {
+	tx, err := m.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("select id from users where id = ? for update", userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("update magic_links set used_at = current_timestamp where user_id = ? and used_at is null", userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("insert into magic_links (user_id, email, token_hash, expires_at) values(?,?,?,?)", userID, email, tokenHash, expiresAt); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+//Consume marca o link como usado e retorna o usuário e o email para o qual ele foi enviado; retorna 0 se o link não vale mais
+func (m MagicLinks) Consume(tokenHash string) (uint64, string, error) {
+	result, err := m.db.Exec("update magic_links set used_at = current_timestamp where token_hash = ? and used_at is null and expires_at > ?", tokenHash, time.Now())
+	if err != nil {
+		return 0, "", err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, "", err
+	}
+
+	sql, err := m.db.Query("select user_id, email from magic_links where token_hash = ?", tokenHash)
+	if err != nil {
+		return 0, "", err
+	}
+	defer sql.Close()
+
+	var userID uint64
+	var email string
+	if sql.Next() {
+		if err = sql.Scan(&userID, &email); err != nil {
+			return 0, "", err
+		}
+	}
+
+	return userID, email, nil
+}
@@ -0,0 +1,143 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+)
+
+//indexMentions resolve os nicks mencionados e grava as menções da publicação, retornando os usuários mencionados.
+//Ficam de fora os nicks que não existem, os que mais de um usuário usa e os usuários que bloquearam o autor.
+func indexMentions(tx *sql.Tx, postID, authorID uint64, mentions []models.Mention) (map[uint64]bool, error) {
+	resolved := map[string]uint64{}
+	mentioned := map[uint64]bool{}
+
+	for _, mention := range mentions {
+		userID, ok := resolved[strings.ToLower(mention.Nick)]
+		if !ok {
+			var err error
+			if userID, err = resolveNick(tx, mention.Nick, authorID); err != nil {
+				return nil, err
+			}
+			resolved[strings.ToLower(mention.Nick)] = userID
+		}
+
+		if userID == 0 {
+			continue
+		}
+
+		if _, err := tx.Exec(
+			"insert into post_mentions (post_id, user_id, field, start, length) values(?,?,?,?,?)",
+			postID, userID, mention.Field, mention.Offset, mention.Length,
+		); err != nil {
+			return nil, err
+		}
+		mentioned[userID] = true
+	}
+
+	return mentioned, nil
+}
+
+//resolveNick retorna 0 quando o nick não identifica um único usuário ou quando o usuário bloqueou o autor
+func resolveNick(tx *sql.Tx, nick string, authorID uint64) (uint64, error) {
+	rows, err := tx.Query(
+		"select u.id from users u where u.nick = ? and u.deleted_at is null and not exists(select 1 from user_blocks b where b.blocker_id = u.id and b.blocked_id = ?) limit 2",
+		nick, authorID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	var ids []uint64
+	for rows.Next() {
+		var id uint64
+		if err = rows.Scan(&id); err != nil {
+			return 0, err
+		}
+		ids = append(ids, id)
+	}
+
+	if len(ids) != 1 {
+		return 0, rows.Err()
+	}
+
+	return ids[0], rows.Err()
+}
+
+//mentionedUsers retorna os usuários já mencionados na publicação
+func mentionedUsers(tx *sql.Tx, postID uint64) (map[uint64]bool, error) {
+	rows, err := tx.Query("select distinct user_id from post_mentions where post_id = ?", postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	mentioned := map[uint64]bool{}
+	for rows.Next() {
+		var userID uint64
+		if err = rows.Scan(&userID); err != nil {
+			return nil, err
+		}
+		mentioned[userID] = true
+	}
+
+	return mentioned, nil
+}
+
+//notifyMentions avisa os usuários mencionados, menos o próprio autor e quem já tinha sido avisado antes
+func notifyMentions(tx *sql.Tx, postID, authorID uint64, mentioned, alreadyNotified map[uint64]bool) error {
+	for userID := range mentioned {
+		if userID == authorID || alreadyNotified[userID] {
+			continue
+		}
+
+		if _, err := tx.Exec(
+			"insert into notifications (user_id, actor_id, type, post_id) values(?,?,?,?)",
+			userID, authorID, models.NotificationMention, postID,
+		); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+//loadMentions preenche as menções das publicações com uma única consulta
+func (p Posts) loadMentions(posts []models.Post) error {
+	if len(posts) == 0 {
+		return nil
+	}
+
+	index := map[uint64]int{}
+	placeholders := make([]string, len(posts))
+	args := make([]interface{}, len(posts))
+	for i, post := range posts {
+		index[post.ID] = i
+		placeholders[i] = "?"
+		args[i] = post.ID
+	}
+
+	rows, err := p.db.Query(
+		"select m.post_id, m.user_id, u.nick, m.field, m.start, m.length from post_mentions m inner join users u on u.id = m.user_id and u.deleted_at is null "+
+			"where m.post_id in ("+strings.Join(placeholders, ",")+") order by m.post_id, m.field desc, m.start",
+		args...,
+	)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID uint64
+		var mention models.Mention
+		if err = rows.Scan(&postID, &mention.UserID, &mention.Nick, &mention.Field, &mention.Offset, &mention.Length); err != nil {
+			return err
+		}
+
+		i := index[postID]
+		posts[i].Mentions = append(posts[i].Mentions, mention)
+	}
+
+	return rows.Err()
+}
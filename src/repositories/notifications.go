@@ -0,0 +1,62 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+type Notifications struct {
+	db *sql.DB
+}
+
+func NewNotificationRep(db *sql.DB) *Notifications {
+	return &Notifications{db}
+}
+
+//Search lista uma página das notificações do usuário, das mais novas para as mais antigas
+func (n Notifications) Search(userID uint64, limit, offset int) ([]models.Notification, error) {
+	rows, err := n.db.Query(
+		"select n.id, n.type, n.actor_id, u.nick, n.post_id, n.read_at, n.created_at from notifications n "+
+			"inner join users u on u.id = n.actor_id and u.deleted_at is null left join posts p on p.id = n.post_id "+
+			"where n.user_id = ? and p.deleted_at is null order by n.id desc limit ? offset ?",
+		userID, limit, offset,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var notifications []models.Notification
+
+	for rows.Next() {
+		var notification models.Notification
+		var postID sql.NullInt64
+		var readAt sql.NullTime
+
+		if err = rows.Scan(
+			&notification.ID,
+			&notification.Type,
+			&notification.ActorID,
+			&notification.ActorNick,
+			&postID,
+			&readAt,
+			&notification.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		notification.PostID = uint64(postID.Int64)
+		if readAt.Valid {
+			notification.ReadAt = &readAt.Time
+		}
+		notifications = append(notifications, notification)
+	}
+
+	return notifications, nil
+}
+
+//MarkAllRead marca como lidas todas as notificações do usuário
+func (n Notifications) MarkAllRead(userID uint64) error {
+	_, err := n.db.Exec("update notifications set read_at = current_timestamp where user_id = ? and read_at is null", userID)
+	return err
+}
@@ -0,0 +1,192 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type OAuthClients struct {
+	db *sql.DB
+}
+
+func NewOAuthClientRep(db *sql.DB) *OAuthClients {
+	return &OAuthClients{db}
+}
+
+func (o OAuthClients) Create(client models.OAuthClient) error {
+	var secretHash interface{}
+	if client.SecretHash != "" {
+		secretHash = client.SecretHash
+	}
+
+	sql, err := o.db.Prepare("insert into oauth_clients (id, secret_hash, name, redirect_uris, owner_id) values(?,?,?,?,?)")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(client.ID, secretHash, client.Name, strings.Join(client.RedirectURIs, " "), client.OwnerID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//GetByID retorna um cliente vazio se ele não existe
+func (o OAuthClients) GetByID(clientID string) (models.OAuthClient, error) {
+	clients, err := o.search("where id = ? and owner_id in (select id from users where deleted_at is null)", clientID)
+	if err != nil || len(clients) == 0 {
+		return models.OAuthClient{}, err
+	}
+
+	return clients[0], nil
+}
+
+func (o OAuthClients) SearchByOwner(ownerID uint64) ([]models.OAuthClient, error) {
+	return o.search("where owner_id = ? order by created_at desc", ownerID)
+}
+
+//Delete retorna false se o cliente não existe ou não pertence ao usuário. Os códigos e
+//refresh tokens do cliente são apagados junto pelas chaves estrangeiras.
+func (o OAuthClients) Delete(clientID string, ownerID uint64) (bool, error) {
+	result, err := o.db.Exec("delete from oauth_clients where id = ? and owner_id = ?", clientID, ownerID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (o OAuthClients) search(where string, args ...interface{}) ([]models.OAuthClient, error) {
+	rows, err := o.db.Query("select id, coalesce(secret_hash, ''), name, redirect_uris, owner_id, created_at from oauth_clients "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var clients []models.OAuthClient
+
+	for rows.Next() {
+		var client models.OAuthClient
+		var redirectURIs string
+
+		if err = rows.Scan(
+			&client.ID,
+			&client.SecretHash,
+			&client.Name,
+			&redirectURIs,
+			&client.OwnerID,
+			&client.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		client.RedirectURIs = strings.Fields(redirectURIs)
+		client.Confidential = client.SecretHash != ""
+		clients = append(clients, client)
+	}
+
+	return clients, nil
+}
+
+type AuthorizationCodes struct {
+	db *sql.DB
+}
+
+func NewAuthorizationCodeRep(db *sql.DB) *AuthorizationCodes {
+	return &AuthorizationCodes{db}
+}
+
+func (a AuthorizationCodes) Create(code models.AuthorizationCode) error {
+	sql, err := a.db.Prepare(
+		"insert into oauth_authorization_codes (code_hash, client_id, user_id, redirect_uri, scopes, code_challenge, code_challenge_method, expires_at) values(?,?,?,?,?,?,?,?)",
+	)
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(
+		code.CodeHash,
+		code.ClientID,
+		code.UserID,
+		code.RedirectURI,
+		strings.Join(code.Scopes, " "),
+		code.CodeChallenge,
+		code.CodeChallengeMethod,
+		code.ExpiresAt,
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//SearchByHash retorna um código vazio se ele não existe
+func (a AuthorizationCodes) SearchByHash(codeHash string) (models.AuthorizationCode, error) {
+	rows, err := a.db.Query(
+		"select id, client_id, user_id, redirect_uri, scopes, code_challenge, code_challenge_method, coalesce(family_id, ''), expires_at, used_at from oauth_authorization_codes where code_hash = ?",
+		codeHash,
+	)
+	if err != nil {
+		return models.AuthorizationCode{}, err
+	}
+	defer rows.Close()
+
+	var code models.AuthorizationCode
+
+	if rows.Next() {
+		var scopes string
+		var usedAt sql.NullTime
+		if err = rows.Scan(
+			&code.ID,
+			&code.ClientID,
+			&code.UserID,
+			&code.RedirectURI,
+			&scopes,
+			&code.CodeChallenge,
+			&code.CodeChallengeMethod,
+			&code.FamilyID,
+			&code.ExpiresAt,
+			&usedAt,
+		); err != nil {
+			return models.AuthorizationCode{}, err
+		}
+
+		code.CodeHash = codeHash
+		code.Scopes = strings.Fields(scopes)
+		if usedAt.Valid {
+			code.UsedAt = &usedAt.Time
+		}
+	}
+
+	return code, nil
+}
+
+//MarkUsed consome o código; retorna false se ele já foi usado ou expirou
+func (a AuthorizationCodes) MarkUsed(id uint64) (bool, error) {
+	result, err := a.db.Exec("update oauth_authorization_codes set used_at = current_timestamp where id = ? and used_at is null and expires_at > ?", id, time.Now())
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+//SetFamily liga o código à família de refresh tokens emitida com ele, para que ela seja revogada se o código for reutilizado
+func (a AuthorizationCodes) SetFamily(id uint64, familyID string) error {
+	_, err := a.db.Exec("update oauth_authorization_codes set family_id = ? where id = ?", familyID, id)
+	return err
+}
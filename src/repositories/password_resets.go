@@ -0,0 +1,79 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+type PasswordResets struct {
+	db *sql.DB
+}
+
+func NewPasswordResetRep(db *sql.DB) *PasswordResets {
+	return &PasswordResets{db}
+}
+
+//Create registra um novo token de redefinição e invalida os que o usuário tinha pedido antes
+func (p PasswordResets) Create(userID uint64, tokenHash string, expiresAt time.Time) error {
+	if _, err := p.db.Exec("update password_resets set used_at = current_timestamp where user_id = ? and used_at is null", userID); err != nil {
+		return err
+	}
+
+	sql, err := p.db.Prepare("insert into password_resets (user_id, token_hash, expires_at) values(?,?,?)")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(userID, tokenHash, expiresAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//SearchValidByHash retorna o dono de um token ainda utilizável, sem consumi-lo; retorna 0 se o token não existe, expirou ou já foi usado
+func (p PasswordResets) SearchValidByHash(tokenHash string) (uint64, error) {
+	sql, err := p.db.Query("select user_id from password_resets where token_hash = ? and used_at is null and expires_at > ?", tokenHash, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	defer sql.Close()
+
+	var userID uint64
+	if sql.Next() {
+		if err = sql.Scan(&userID); err != nil {
+			return 0, err
+		}
+	}
+
+	return userID, nil
+}
+
+//Consume marca o token como usado e retorna o dono dele; retorna 0 se o token não existe, expirou ou já foi usado
+func (p PasswordResets) Consume(tokenHash string) (uint64, error) {
+	result, err := p.db.Exec("update password_resets set used_at = current_timestamp where token_hash = ? and used_at is null and expires_at > ?", tokenHash, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, err
+	}
+
+	sql, err := p.db.Query("select user_id from password_resets where token_hash = ?", tokenHash)
+	if err != nil {
+		return 0, err
+	}
+	defer sql.Close()
+
+	var userID uint64
+	if sql.Next() {
+		if err = sql.Scan(&userID); err != nil {
+			return 0, err
+		}
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,121 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type PersonalAccessTokens struct {
+	db *sql.DB
+}
+
+func NewPersonalAccessTokenRep(db *sql.DB) *PersonalAccessTokens {
+	return &PersonalAccessTokens{db}
+}
+
+func (p PersonalAccessTokens) Create(token models.PersonalAccessToken) (uint64, error) {
+	sql, err := p.db.Prepare("insert into personal_access_tokens (user_id, name, token_prefix, token_hash, scopes, expires_at) values(?,?,?,?,?,?)")
+	if err != nil {
+		return 0, err
+	}
+	defer sql.Close()
+
+	result, err := sql.Exec(token.UserID, token.Name, token.Prefix, token.TokenHash, strings.Join(token.Scopes, " "), token.ExpiresAt)
+	if err != nil {
+		return 0, err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastID), nil
+}
+
+//SearchByUser lista os tokens do usuário que ainda não foram revogados
+func (p PersonalAccessTokens) SearchByUser(userID uint64) ([]models.PersonalAccessToken, error) {
+	return p.search("where user_id = ? and revoked_at is null order by id desc", userID)
+}
+
+//SearchActiveByHash retorna o token se ele existe, não foi revogado e não expirou
+func (p PersonalAccessTokens) SearchActiveByHash(tokenHash string) (models.PersonalAccessToken, error) {
+	tokens, err := p.search("where token_hash = ? and revoked_at is null and (expires_at is null or expires_at > ?)", tokenHash, time.Now())
+	if err != nil || len(tokens) == 0 {
+		return models.PersonalAccessToken{}, err
+	}
+
+	return tokens[0], nil
+}
+
+//Revoke retorna false se o token não existe ou não pertence ao usuário
+func (p PersonalAccessTokens) Revoke(tokenID, userID uint64) (bool, error) {
+	result, err := p.db.Exec("update personal_access_tokens set revoked_at = current_timestamp where id = ? and user_id = ? and revoked_at is null", tokenID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (p PersonalAccessTokens) RevokeAllFromUser(userID uint64) error {
+	_, err := p.db.Exec("update personal_access_tokens set revoked_at = current_timestamp where user_id = ? and revoked_at is null", userID)
+	return err
+}
+
+//Touch atualiza o último uso do token, no máximo uma vez por minuto
+func (p PersonalAccessTokens) Touch(tokenID uint64) error {
+	_, err := p.db.Exec(
+		"update personal_access_tokens set last_used_at = current_timestamp where id = ? and (last_used_at is null or last_used_at < current_timestamp - interval 1 minute)",
+		tokenID,
+	)
+	return err
+}
+
+func (p PersonalAccessTokens) search(filter string, args ...interface{}) ([]models.PersonalAccessToken, error) {
+	rows, err := p.db.Query("select id, user_id, name, token_prefix, token_hash, scopes, expires_at, last_used_at, created_at from personal_access_tokens "+filter, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tokens []models.PersonalAccessToken
+
+	for rows.Next() {
+		var token models.PersonalAccessToken
+		var scopes string
+		var expiresAt, lastUsedAt sql.NullTime
+		if err = rows.Scan(
+			&token.ID,
+			&token.UserID,
+			&token.Name,
+			&token.Prefix,
+			&token.TokenHash,
+			&scopes,
+			&expiresAt,
+			&lastUsedAt,
+			&token.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		token.Scopes = strings.Fields(scopes)
+		if expiresAt.Valid {
+			token.ExpiresAt = &expiresAt.Time
+		}
+		if lastUsedAt.Valid {
+			token.LastUsedAt = &lastUsedAt.Time
+		}
+
+		tokens = append(tokens, token)
+	}
+
+	return tokens, nil
+}
@@ -3,6 +3,8 @@ package repositories
 import (
 	"api/src/models"
 	"database/sql"
+	"strings"
+	"time"
 )
 
 type Posts struct {
@@ -13,14 +15,24 @@ func NewPostRep(db *sql.DB) *Posts {
 	return &Posts{db}
 }
 
+//CreatePost grava a publicação, indexa as hashtags e as menções dela e avisa os mencionados, tudo na mesma transação.
+//Rascunhos e publicações agendadas só avisam os mencionados quando forem publicados.
 func (p Posts) CreatePost(post models.Post) (uint64, error){
-	sql, err := p.db.Prepare("insert into posts (title, content, author_id) values(?,?,?)")
+	var repostOf interface{}
+	if post.RepostOf != 0 {
+		repostOf = post.RepostOf
+	}
+
+	tx, err := p.db.Begin()
 	if err != nil {
 		return 0, err
 	}
-	defer sql.Close()
+	defer tx.Rollback()
 
-	result, err := sql.Exec(post.Title, post.Content, post.AuthorID)
+	result, err := tx.Exec(
+		"insert into posts (title, content, author_id, kind, repost_of, status, publish_at) values(?,?,?,?,?,?,?)",
+		post.Title, post.Content, post.AuthorID, post.Kind, repostOf, post.Status, post.PublishAt,
+	)
 	if err != nil {
 		return 0, err
 	}
@@ -30,144 +42,471 @@ func (p Posts) CreatePost(post models.Post) (uint64, error){
 		return 0, err
 	}
 
-	return uint64(lastID), nil
-}
+	if err = indexHashtags(tx, uint64(lastID), post.Hashtags); err != nil {
+		return 0, err
+	}
 
-func (p Posts) GetOnePost(postID uint64) (models.Post, error){
-	sql, err := p.db.Query("select p.*, u.nick from posts p inner join users u on u.id = p.author_id where p.id = ?", postID)
+	mentioned, err := indexMentions(tx, uint64(lastID), post.AuthorID, post.Mentions)
 	if err != nil {
+		return 0, err
+	}
+
+	if post.Status == models.PostStatusPublished {
+		if err = notifyMentions(tx, uint64(lastID), post.AuthorID, mentioned, nil); err != nil {
+			return 0, err
+		}
+	}
+
+	return uint64(lastID), tx.Commit()
+}
+
+//postQuery é a consulta usada por search; os três primeiros ? recebem o usuário que está vendo as publicações.
+//A publicação original de reposts e citações vem junto, e os reposts cujo original foi apagado ficam de fora.
+//Rascunhos e publicações agendadas só aparecem para o próprio autor; nada que esteja na lixeira aparece.
+const postQuery = "select p.id, p.title, p.content, p.author_id, p.likes, p.created_at, u.nick, coalesce(u.avatar_key, ''), " +
+	"(select count(*) from comments c inner join users cu on cu.id = c.author_id and cu.deleted_at is null where c.post_id = p.id), " +
+	"exists(select 1 from post_likes l where l.post_id = p.id and l.user_id = ?), " +
+	"exists(select 1 from bookmarks b where b.post_id = p.id and b.user_id = ?), " +
+	"p.kind, o.id, o.title, o.content, o.author_id, ou.nick, ou.avatar_key, o.created_at, " +
+	"(select group_concat(h.tag separator ' ') from post_hashtags h where h.post_id = p.id), " +
+	"p.status, p.publish_at, p.edited_at, " +
+	"(select count(*) from post_revisions r where r.post_id = p.id) " +
+	"from posts p inner join users u on u.id = p.author_id and u.deleted_at is null " +
+	"left join (posts o inner join users ou on ou.id = o.author_id and ou.deleted_at is null) on o.id = p.repost_of and o.deleted_at is null " +
+	"where p.deleted_at is null and (p.kind <> 'repost' or o.id is not null) and (p.status = 'published' or p.author_id = ?) and "
+
+func (p Posts) GetOnePost(postID, viewerID uint64) (models.Post, error){
+	posts, err := p.search("p.id = ?", viewerID, postID)
+	if err != nil || len(posts) == 0 {
 		return models.Post{}, err
 	}
-	defer sql.Close()
 
-	var post models.Post
+	return posts[0], nil
+}
 
-	if sql.Next() {
-		if err = sql.Scan(
-			&post.ID,
-			&post.Title, 
-			&post.Content,
-			&post.AuthorID,
-			&post.Likes,
-			&post.CreatedAt,
-			&post.AuthorNick,
-		); err != nil {
-			return models.Post{}, err
+func (p Posts) SearchPosts(userID uint64) ([]models.Post, error){
+	return p.search(
+		"(p.author_id = ? or p.author_id in (select user_id from followers where follower_id = ?)) order by p.created_at desc, p.id desc",
+		userID, userID, userID,
+	)
+}
+
+//Update altera a publicação e refaz os índices de hashtags e menções dela; só quem passou a ser mencionado é avisado.
+//authorID é o autor da publicação e editorID quem está editando, que pode ser um moderador. Um rascunho que passa
+//a publicado ganha a data de agora e avisa todos os mencionados. Quando uma publicação já publicada muda de título
+//ou de conteúdo, a versão anterior fica guardada em post_revisions.
+func (p Posts) Update(postID, authorID, editorID uint64, post models.Post) error{
+	tx, err := p.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var previous models.Post
+	if err = tx.QueryRow("select title, content, status from posts where id = ? for update", postID).Scan(
+		&previous.Title,
+		&previous.Content,
+		&previous.Status,
+	); err != nil {
+		return err
+	}
+
+	//rascunhos podem mudar à vontade; o histórico só vale para o que os leitores já viram
+	edited := previous.Status == models.PostStatusPublished && (previous.Title != post.Title || previous.Content != post.Content)
+	if edited {
+		if err = saveRevision(tx, postID, editorID, previous.Title, previous.Content); err != nil {
+			return err
 		}
 	}
 
-	return post, nil
+	publishing := previous.Status != models.PostStatusPublished && post.Status == models.PostStatusPublished
+	if _, err = tx.Exec(
+		"update posts set title=?, content=?, status=?, publish_at=?, created_at = if(?, current_timestamp, created_at), "+
+			"edited_at = if(?, current_timestamp, edited_at) where id = ?",
+		post.Title, post.Content, post.Status, post.PublishAt, publishing, edited, postID,
+	); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("delete from post_hashtags where post_id = ?", postID); err != nil {
+		return err
+	}
+
+	if err = indexHashtags(tx, postID, post.Hashtags); err != nil {
+		return err
+	}
+
+	previouslyMentioned, err := mentionedUsers(tx, postID)
+	if err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("delete from post_mentions where post_id = ?", postID); err != nil {
+		return err
+	}
+
+	mentioned, err := indexMentions(tx, postID, authorID, post.Mentions)
+	if err != nil {
+		return err
+	}
+
+	if post.Status == models.PostStatusPublished {
+		//enquanto não foi publicada ninguém foi avisado
+		if previous.Status != models.PostStatusPublished {
+			previouslyMentioned = nil
+		}
+
+		if err = notifyMentions(tx, postID, authorID, mentioned, previouslyMentioned); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
 }
 
-func (p Posts) SearchPosts(userID uint64) ([]models.Post, error){
-	sql, err := p.db.Query("select distinct p.*, u.nick from posts p inner join users u on u.id = p.author_id inner join followers f on p.author_id = f.user_id where u.id = ? or f.follower_id = ? order by 1 desc", userID, userID)
+//PublishDue publica as publicações agendadas cujo horário já chegou e avisa os mencionados nelas.
+//A situação é conferida com a linha travada, então cada publicação sai uma única vez. Retorna quantas saíram.
+func (p Posts) PublishDue() (int, error){
+	tx, err := p.db.Begin()
 	if err != nil {
-		return nil , err
+		return 0, err
 	}
-	defer sql.Close()
+	defer tx.Rollback()
 
-	var posts []models.Post
+	rows, err := tx.Query("select id, author_id from posts where status = ? and publish_at <= current_timestamp and deleted_at is null for update", models.PostStatusScheduled)
+	if err != nil {
+		return 0, err
+	}
 
-	for sql.Next(){
+	var due []models.Post
+	for rows.Next() {
 		var post models.Post
-		if err = sql.Scan(
-			&post.ID,
-			&post.Title, 
-			&post.Content,
-			&post.AuthorID,
-			&post.Likes,
-			&post.CreatedAt,
-			&post.AuthorNick,
+		if err = rows.Scan(&post.ID, &post.AuthorID); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		due = append(due, post)
+	}
+	rows.Close()
+
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, post := range due {
+		if _, err = tx.Exec(
+			"update posts set status = ?, created_at = publish_at, publish_at = null where id = ?",
+			models.PostStatusPublished, post.ID,
 		); err != nil {
-			return nil, err
+			return 0, err
 		}
 
-		posts = append(posts, post)
+		mentioned, err := mentionedUsers(tx, post.ID)
+		if err != nil {
+			return 0, err
+		}
+
+		if err = notifyMentions(tx, post.ID, post.AuthorID, mentioned, nil); err != nil {
+			return 0, err
+		}
 	}
-	return posts, nil
+
+	return len(due), tx.Commit()
 }
 
-func (p Posts) Update(postID uint64, post models.Post) error{
-	sql, err := p.db.Prepare("update posts set title=?, content=? where id = ?")
+//SearchByHashtag lista uma página das publicações com a hashtag, das mais novas para as mais antigas
+func (p Posts) SearchByHashtag(tag string, viewerID uint64, limit, offset int) ([]models.Post, error){
+	return p.search("p.id in (select post_id from post_hashtags where tag = ?) order by p.created_at desc, p.id desc limit ? offset ?", viewerID, tag, limit, offset)
+}
+
+//DeletePost manda a publicação para a lixeira; deletedBy é quem excluiu, que pode ser um moderador
+func (p Posts) DeletePost(postID, deletedBy uint64) error{
+	sql, err := p.db.Prepare("update posts set deleted_at = current_timestamp, deleted_by = ? where id = ? and deleted_at is null")
 	if err != nil {
 		return err
 	}
 	defer sql.Close()
 
-	if _, err = sql.Exec(post.Title, post.Content, postID); err != nil {
+	if _, err = sql.Exec(deletedBy, postID); err != nil {
 		return err
 	}
 
 	return nil
 }
 
-func (p Posts) DeletePost(postID uint64) error{
-	sql, err := p.db.Prepare("delete from posts where id = ?")
+//CreateRepost cria o repost simples da publicação. O índice único em repost_key só deixa existir um repost
+//ativo por usuário e publicação, então o retorno é zero quando ele já existe
+func (p Posts) CreateRepost(authorID, originalID uint64) (uint64, error){
+	result, err := p.db.Exec(
+		"insert ignore into posts (title, content, author_id, kind, repost_of, status) values('', '', ?, ?, ?, ?)",
+		authorID, models.PostKindRepost, originalID, models.PostStatusPublished,
+	)
 	if err != nil {
-		return err
+		return 0, err
 	}
-	defer sql.Close()
 
-	if _, err = sql.Exec(postID); err != nil {
-		return err
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return 0, err
 	}
 
-	return nil
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastID), nil
+}
+
+//DeleteRepost apaga de vez um repost simples
+func (p Posts) DeleteRepost(postID uint64) error{
+	_, err := p.db.Exec("delete from posts where id = ? and kind = ?", postID, models.PostKindRepost)
+	return err
 }
 
-func (p Posts) GetUserPosts(userID uint64) ([]models.Post, error){
-	sql, err := p.db.Query("select p.*, u.nick from posts p join users u on u.id = p.author_id where p.author_id = ?", userID)
+//SearchTrash lista as publicações que o próprio autor mandou para a lixeira depois de since, das excluídas por último para as mais antigas
+func (p Posts) SearchTrash(authorID uint64, since time.Time) ([]models.Post, error){
+	rows, err := p.db.Query(
+		"select id, title, content, author_id, kind, status, created_at, deleted_at from posts "+
+			"where author_id = ? and deleted_by = author_id and deleted_at > ? order by deleted_at desc",
+		authorID, since,
+	)
 	if err != nil {
 		return nil, err
 	}
-	defer sql.Close()
+	defer rows.Close()
 
 	var posts []models.Post
 
-	for sql.Next(){
+	for rows.Next(){
 		var post models.Post
-
-		if err = sql.Scan(
-			&post.ID,
-			&post.Title, 
-			&post.Content,
-			&post.AuthorID,
-			&post.Likes,
-			&post.CreatedAt,
-			&post.AuthorNick,
-		); err != nil {
+		var deletedAt time.Time
+		if err = rows.Scan(&post.ID, &post.Title, &post.Content, &post.AuthorID, &post.Kind, &post.Status, &post.CreatedAt, &deletedAt); err != nil {
 			return nil, err
 		}
+		post.DeletedAt = &deletedAt
 
 		posts = append(posts, post)
 	}
-	return posts, nil
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return posts, p.loadAttachments(posts)
 }
 
-func (p Posts) Like(postID uint64) error{
-	sql, err := p.db.Prepare("update posts p set likes = likes + 1 where id = ?")
+//GetDeleted retorna a publicação que foi para a lixeira depois de since, ou uma publicação vazia
+func (p Posts) GetDeleted(postID uint64, since time.Time) (models.Post, error){
+	rows, err := p.db.Query("select id, author_id, coalesce(deleted_by, 0), deleted_at from posts where id = ? and deleted_at > ?", postID, since)
 	if err != nil {
-		return err
+		return models.Post{}, err
 	}
-	defer sql.Close()
+	defer rows.Close()
 
-	if _, err := sql.Exec(postID); err != nil {
-		return err
-	} 
+	var post models.Post
+	if rows.Next() {
+		var deletedAt time.Time
+		if err = rows.Scan(&post.ID, &post.AuthorID, &post.DeletedBy, &deletedAt); err != nil {
+			return models.Post{}, err
+		}
+		post.DeletedAt = &deletedAt
+	}
 
-	return nil
+	return post, rows.Err()
 }
 
-func (p Posts) Unlike(postID uint64) error{
-	sql, err := p.db.Prepare("update posts p set likes = CASE WHEN likes > 0 THEN likes - 1 ELSE 0 END where id = ?")
+//Restore tira a publicação da lixeira e retorna false quando ela é um repost de algo que o autor já repostou de novo
+func (p Posts) Restore(postID uint64) (bool, error){
+	result, err := p.db.Exec("update ignore posts set deleted_at = null, deleted_by = null where id = ?", postID)
 	if err != nil {
-		return err
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (p Posts) GetUserPosts(userID, viewerID uint64) ([]models.Post, error){
+	return p.search("p.author_id = ? order by p.created_at desc, p.id desc", viewerID, userID)
+}
+
+//Like registra a curtida do usuário; curtir de novo não muda nada. Retorna se a curtida é nova.
+func (p Posts) Like(postID, userID uint64) (bool, error){
+	tx, err := p.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("insert ignore into post_likes (user_id, post_id) values(?,?)", userID, postID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	if _, err = tx.Exec("update posts set likes = likes + 1 where id = ?", postID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+//Unlike remove apenas a curtida do próprio usuário. Retorna se havia uma curtida para remover.
+func (p Posts) Unlike(postID, userID uint64) (bool, error){
+	tx, err := p.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec("delete from post_likes where user_id = ? and post_id = ?", userID, postID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	if _, err = tx.Exec("update posts set likes = likes - 1 where id = ? and likes > 0", postID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+//GetLikes lista quem curtiu a publicação, das curtidas mais recentes para as mais antigas
+func (p Posts) GetLikes(postID uint64) ([]models.User, error){
+	sql, err := p.db.Query("select u.id, u.name, u.nick, u.created_at from users u inner join post_likes l on u.id = l.user_id where l.post_id = ? and u.deleted_at is null order by l.created_at desc", postID)
+	if err != nil {
+		return nil, err
 	}
 	defer sql.Close()
 
-	if _, err := sql.Exec(postID); err != nil {
-		return err
+	var users []models.User
+
+	for sql.Next(){
+		var user models.User
+		if err = sql.Scan(&user.ID, &user.Name, &user.Nick, &user.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		users = append(users, user)
 	}
 
-	return nil
-}
\ No newline at end of file
+	return users, nil
+}
+
+//SearchMentions lista uma página das publicações que mencionam o usuário, das mais novas para as mais antigas
+func (p Posts) SearchMentions(userID, viewerID uint64, limit, offset int) ([]models.Post, error){
+	return p.search("p.id in (select post_id from post_mentions where user_id = ?) order by p.created_at desc, p.id desc limit ? offset ?", viewerID, userID, limit, offset)
+}
+
+//SearchRepost retorna o repost simples que o usuário fez da publicação, ou uma publicação vazia
+func (p Posts) SearchRepost(postID, userID uint64) (models.Post, error){
+	posts, err := p.search("p.repost_of = ? and p.author_id = ? and p.kind = ?", userID, postID, userID, models.PostKindRepost)
+	if err != nil || len(posts) == 0 {
+		return models.Post{}, err
+	}
+
+	return posts[0], nil
+}
+
+//search completa postQuery com as condições informadas; viewerID é sempre o usuário que está vendo
+func (p Posts) search(conditions string, viewerID uint64, args ...interface{}) ([]models.Post, error){
+	rows, err := p.db.Query(postQuery+conditions, append([]interface{}{viewerID, viewerID, viewerID}, args...)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var posts []models.Post
+
+	for rows.Next(){
+		var post models.Post
+		var original models.EmbeddedPost
+		var originalID, originalAuthorID sql.NullInt64
+		var originalTitle, originalContent, originalAuthorNick, originalAuthorAvatar sql.NullString
+		var originalCreatedAt sql.NullTime
+		var hashtags sql.NullString
+		var publishAt, editedAt sql.NullTime
+
+		if err = rows.Scan(
+			&post.ID,
+			&post.Title,
+			&post.Content,
+			&post.AuthorID,
+			&post.Likes,
+			&post.CreatedAt,
+			&post.AuthorNick,
+			&post.AuthorAvatarKey,
+			&post.Comments,
+			&post.LikedByMe,
+			&post.BookmarkedByMe,
+			&post.Kind,
+			&originalID,
+			&originalTitle,
+			&originalContent,
+			&originalAuthorID,
+			&originalAuthorNick,
+			&originalAuthorAvatar,
+			&originalCreatedAt,
+			&hashtags,
+			&post.Status,
+			&publishAt,
+			&editedAt,
+			&post.RevisionCount,
+		); err != nil {
+			return nil, err
+		}
+
+		post.Hashtags = strings.Fields(hashtags.String)
+		if publishAt.Valid {
+			post.PublishAt = &publishAt.Time
+		}
+		if editedAt.Valid {
+			post.EditedAt = &editedAt.Time
+		}
+
+		if post.Kind != models.PostKindPost {
+			if originalID.Valid {
+				original = models.EmbeddedPost{
+					ID:           uint64(originalID.Int64),
+					Title:        originalTitle.String,
+					Content:      originalContent.String,
+					AuthorID:     uint64(originalAuthorID.Int64),
+					AuthorNick:   originalAuthorNick.String,
+					CreatedAt:    originalCreatedAt.Time,
+
+					AuthorAvatarKey: originalAuthorAvatar.String,
+				}
+				post.RepostOf = original.ID
+			} else {
+				original = models.UnavailablePost()
+			}
+			post.Original = &original
+		}
+
+		posts = append(posts, post)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err = p.loadMentions(posts); err != nil {
+		return nil, err
+	}
+
+	return posts, p.loadAttachments(posts)
+}
@@ -0,0 +1,177 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"strings"
+	"time"
+)
+
+type RefreshTokens struct {
+	db *sql.DB
+}
+
+func NewRefreshTokenRep(db *sql.DB) *RefreshTokens {
+	return &RefreshTokens{db}
+}
+
+func (r RefreshTokens) Create(token models.RefreshToken) error {
+	var clientID interface{}
+	if token.ClientID != "" {
+		clientID = token.ClientID
+	}
+
+	sql, err := r.db.Prepare("insert into refresh_tokens (user_id, family_id, client_id, scopes, token_hash, expires_at) values(?,?,?,?,?,?)")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(token.UserID, token.FamilyID, clientID, strings.Join(token.Scopes, " "), token.TokenHash, token.ExpiresAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RefreshTokens) SearchByHash(tokenHash string) (models.RefreshToken, error) {
+	rows, err := r.db.Query("select id, user_id, family_id, coalesce(client_id, ''), scopes, token_hash, expires_at, used_at, revoked_at, created_at from refresh_tokens where token_hash = ?", tokenHash)
+	if err != nil {
+		return models.RefreshToken{}, err
+	}
+	defer rows.Close()
+
+	var token models.RefreshToken
+
+	if rows.Next() {
+		var scopes string
+		var usedAt, revokedAt sql.NullTime
+		if err = rows.Scan(
+			&token.ID,
+			&token.UserID,
+			&token.FamilyID,
+			&token.ClientID,
+			&scopes,
+			&token.TokenHash,
+			&token.ExpiresAt,
+			&usedAt,
+			&revokedAt,
+			&token.CreatedAt,
+		); err != nil {
+			return models.RefreshToken{}, err
+		}
+
+		token.Scopes = strings.Fields(scopes)
+		if usedAt.Valid {
+			token.UsedAt = &usedAt.Time
+		}
+		if revokedAt.Valid {
+			token.RevokedAt = &revokedAt.Time
+		}
+	}
+
+	return token, nil
+}
+
+//MarkUsed consome o token; retorna false se outra requisição já tiver usado o mesmo token
+func (r RefreshTokens) MarkUsed(id uint64) (bool, error) {
+	sql, err := r.db.Prepare("update refresh_tokens set used_at = current_timestamp where id = ? and used_at is null and revoked_at is null")
+	if err != nil {
+		return false, err
+	}
+	defer sql.Close()
+
+	result, err := sql.Exec(id)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+//SearchGrantsByUser lista os aplicativos que ainda têm algum refresh token válido do usuário, um por aplicativo,
+//com os escopos do token mais recente
+func (r RefreshTokens) SearchGrantsByUser(userID uint64) ([]models.OAuthGrant, error) {
+	rows, err := r.db.Query(
+		`select c.id, c.name, t.scopes, t.created_at from refresh_tokens t
+		inner join oauth_clients c on c.id = t.client_id
+		where t.user_id = ? and t.used_at is null and t.revoked_at is null and t.expires_at > ?
+		order by t.created_at desc, t.id desc`,
+		userID, time.Now(),
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var grants []models.OAuthGrant
+	seen := map[string]bool{}
+
+	for rows.Next() {
+		var grant models.OAuthGrant
+		var scopes string
+
+		if err = rows.Scan(&grant.ClientID, &grant.ClientName, &scopes, &grant.LastUsedAt); err != nil {
+			return nil, err
+		}
+
+		//o aplicativo pode ter mais de uma família (uma por autorização); vale a usada por último
+		if seen[grant.ClientID] {
+			continue
+		}
+		seen[grant.ClientID] = true
+
+		grant.Scopes = strings.Fields(scopes)
+		grants = append(grants, grant)
+	}
+
+	return grants, nil
+}
+
+//RevokeClient revoga todos os refresh tokens que o usuário deu ao aplicativo; retorna false se não havia nenhum ativo
+func (r RefreshTokens) RevokeClient(userID uint64, clientID string) (bool, error) {
+	result, err := r.db.Exec("update refresh_tokens set revoked_at = current_timestamp where user_id = ? and client_id = ? and revoked_at is null", userID, clientID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+func (r RefreshTokens) RevokeFamily(familyID string) error {
+	sql, err := r.db.Prepare("update refresh_tokens set revoked_at = current_timestamp where family_id = ? and revoked_at is null")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(familyID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r RefreshTokens) RevokeAllFromUser(userID uint64) error {
+	sql, err := r.db.Prepare("update refresh_tokens set revoked_at = current_timestamp where user_id = ? and revoked_at is null")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(userID); err != nil {
+		return err
+	}
+
+	return nil
+}
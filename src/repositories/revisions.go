@@ -0,0 +1,73 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+type Revisions struct {
+	db *sql.DB
+}
+
+func NewRevisionRep(db *sql.DB) *Revisions {
+	return &Revisions{db}
+}
+
+//Search lista as versões anteriores da publicação, da mais antiga para a mais nova
+func (r Revisions) Search(postID uint64) ([]models.PostRevision, error) {
+	rows, err := r.db.Query("select revision, title, content, editor_id, replaced_at from post_revisions where post_id = ? order by revision", postID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var revisions []models.PostRevision
+
+	for rows.Next() {
+		revision, err := scanRevision(rows)
+		if err != nil {
+			return nil, err
+		}
+
+		revisions = append(revisions, revision)
+	}
+
+	return revisions, rows.Err()
+}
+
+//GetByNumber retorna a versão anterior com o número informado, ou uma revisão vazia
+func (r Revisions) GetByNumber(postID, number uint64) (models.PostRevision, error) {
+	rows, err := r.db.Query("select revision, title, content, editor_id, replaced_at from post_revisions where post_id = ? and revision = ?", postID, number)
+	if err != nil {
+		return models.PostRevision{}, err
+	}
+	defer rows.Close()
+
+	if rows.Next() {
+		return scanRevision(rows)
+	}
+
+	return models.PostRevision{}, rows.Err()
+}
+
+func scanRevision(rows *sql.Rows) (models.PostRevision, error) {
+	var revision models.PostRevision
+	var editorID sql.NullInt64
+
+	if err := rows.Scan(&revision.Revision, &revision.Title, &revision.Content, &editorID, &revision.ReplacedAt); err != nil {
+		return models.PostRevision{}, err
+	}
+	revision.EditorID = uint64(editorID.Int64)
+
+	return revision, nil
+}
+
+//saveRevision guarda a versão que está sendo substituída; é chamado dentro da transação que altera a publicação
+func saveRevision(tx *sql.Tx, postID, editorID uint64, title, content string) error {
+	_, err := tx.Exec(
+		"insert into post_revisions (post_id, revision, title, content, editor_id) "+
+			"select ?, coalesce(max(revision), 0) + 1, ?, ?, ? from post_revisions where post_id = ?",
+		postID, title, content, editorID, postID,
+	)
+	return err
+}
@@ -0,0 +1,56 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+type RevokedTokens struct {
+	db *sql.DB
+}
+
+func NewRevokedTokenRep(db *sql.DB) *RevokedTokens {
+	return &RevokedTokens{db}
+}
+
+//Revoke invalida um único access token até a data em que ele expiraria de qualquer forma
+func (r RevokedTokens) Revoke(tokenID string, userID uint64, expiresAt time.Time) error {
+	//aproveita a escrita para limpar as entradas que já não servem para nada
+	if _, err := r.db.Exec("delete from revoked_tokens where expires_at < ?", time.Now()); err != nil {
+		return err
+	}
+
+	sql, err := r.db.Prepare("insert ignore into revoked_tokens (jti, user_id, expires_at) values(?,?,?)")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(tokenID, userID, expiresAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//IsRevoked verifica se o token foi revogado individualmente ou se foi emitido antes de um "sair de todos os dispositivos".
+//O iat e tokens_revoked_at só guardam segundos, então um token emitido no mesmo segundo da revogação também é recusado
+func (r RevokedTokens) IsRevoked(tokenID string, userID uint64, issuedAt time.Time) (bool, error) {
+	rows, err := r.db.Query(
+		"select exists(select 1 from revoked_tokens where jti = ?) or exists(select 1 from users where id = ? and tokens_revoked_at >= from_unixtime(?))",
+		tokenID, userID, issuedAt.Unix(),
+	)
+	if err != nil {
+		return false, err
+	}
+	defer rows.Close()
+
+	var revoked bool
+	if rows.Next() {
+		if err = rows.Scan(&revoked); err != nil {
+			return false, err
+		}
+	}
+
+	return revoked, nil
+}
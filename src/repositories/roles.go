@@ -0,0 +1,80 @@
+package repositories
+
+import (
+	"database/sql"
+)
+
+type Roles struct {
+	db *sql.DB
+}
+
+func NewRoleRep(db *sql.DB) *Roles {
+	return &Roles{db}
+}
+
+func (r Roles) SearchByUser(userID uint64) ([]string, error) {
+	sql, err := r.db.Query("select r.name from roles r inner join user_roles ur on ur.role_id = r.id where ur.user_id = ?", userID)
+	if err != nil {
+		return nil, err
+	}
+	defer sql.Close()
+
+	roles := []string{}
+
+	for sql.Next() {
+		var role string
+		if err = sql.Scan(&role); err != nil {
+			return nil, err
+		}
+
+		roles = append(roles, role)
+	}
+
+	return roles, nil
+}
+
+//Assign dá o papel ao usuário; quem chama confere antes com Exists que o papel existe
+func (r Roles) Assign(userID uint64, role string) error {
+	sql, err := r.db.Prepare("insert ignore into user_roles (user_id, role_id) select ?, id from roles where name = ?")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(userID, role); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r Roles) Remove(userID uint64, role string) error {
+	sql, err := r.db.Prepare("delete ur from user_roles ur inner join roles r on r.id = ur.role_id where ur.user_id = ? and r.name = ?")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(userID, role); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (r Roles) Exists(role string) (bool, error) {
+	sql, err := r.db.Query("select exists(select 1 from roles where name = ?)", role)
+	if err != nil {
+		return false, err
+	}
+	defer sql.Close()
+
+	var exists bool
+	if sql.Next() {
+		if err = sql.Scan(&exists); err != nil {
+			return false, err
+		}
+	}
+
+	return exists, nil
+}
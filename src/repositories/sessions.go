@@ -0,0 +1,126 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+type Sessions struct {
+	db *sql.DB
+}
+
+func NewSessionRep(db *sql.DB) *Sessions {
+	return &Sessions{db}
+}
+
+func (s Sessions) Create(session models.Session) (uint64, error) {
+	sql, err := s.db.Prepare("insert into sessions (user_id, family_id, user_agent, ip) values(?,?,?,?)")
+	if err != nil {
+		return 0, err
+	}
+	defer sql.Close()
+
+	result, err := sql.Exec(session.UserID, session.FamilyID, session.UserAgent, session.IP)
+	if err != nil {
+		return 0, err
+	}
+
+	lastID, err := result.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	return uint64(lastID), nil
+}
+
+//GetByID retorna uma sessão vazia se ela não existe
+func (s Sessions) GetByID(sessionID uint64) (models.Session, error) {
+	sessions, err := s.search("where id = ?", sessionID)
+	if err != nil || len(sessions) == 0 {
+		return models.Session{}, err
+	}
+
+	return sessions[0], nil
+}
+
+//SearchByFamily retorna a sessão dona da família de refresh tokens, ou uma sessão vazia
+func (s Sessions) SearchByFamily(familyID string) (models.Session, error) {
+	sessions, err := s.search("where family_id = ?", familyID)
+	if err != nil || len(sessions) == 0 {
+		return models.Session{}, err
+	}
+
+	return sessions[0], nil
+}
+
+//SearchActiveByUser lista as sessões não encerradas que foram usadas depois de since
+func (s Sessions) SearchActiveByUser(userID uint64, since time.Time) ([]models.Session, error) {
+	return s.search("where user_id = ? and revoked_at is null and last_seen_at > ? order by last_seen_at desc", userID, since)
+}
+
+//Touch atualiza o último acesso da sessão
+func (s Sessions) Touch(sessionID uint64) error {
+	_, err := s.db.Exec("update sessions set last_seen_at = current_timestamp where id = ?", sessionID)
+	return err
+}
+
+//Revoke retorna false se a sessão não existe, não pertence ao usuário ou já foi encerrada
+func (s Sessions) Revoke(sessionID, userID uint64) (bool, error) {
+	result, err := s.db.Exec("update sessions set revoked_at = current_timestamp where id = ? and user_id = ? and revoked_at is null", sessionID, userID)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+func (s Sessions) RevokeFamily(familyID string) error {
+	_, err := s.db.Exec("update sessions set revoked_at = current_timestamp where family_id = ? and revoked_at is null", familyID)
+	return err
+}
+
+func (s Sessions) RevokeAllFromUser(userID uint64) error {
+	_, err := s.db.Exec("update sessions set revoked_at = current_timestamp where user_id = ? and revoked_at is null", userID)
+	return err
+}
+
+func (s Sessions) search(where string, args ...interface{}) ([]models.Session, error) {
+	rows, err := s.db.Query("select id, user_id, family_id, user_agent, ip, created_at, last_seen_at, revoked_at from sessions "+where, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+
+	for rows.Next() {
+		var session models.Session
+		var revokedAt sql.NullTime
+
+		if err = rows.Scan(
+			&session.ID,
+			&session.UserID,
+			&session.FamilyID,
+			&session.UserAgent,
+			&session.IP,
+			&session.CreatedAt,
+			&session.LastSeenAt,
+			&revokedAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if revokedAt.Valid {
+			session.RevokedAt = &revokedAt.Time
+		}
+		sessions = append(sessions, session)
+	}
+
+	return sessions, nil
+}
@@ -0,0 +1,99 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+	"time"
+)
+
+type SigningKeys struct {
+	db *sql.DB
+}
+
+func NewSigningKeyRep(db *sql.DB) *SigningKeys {
+	return &SigningKeys{db}
+}
+
+func (s SigningKeys) Create(key models.SigningKey) error {
+	sql, err := s.db.Prepare("insert into signing_keys (kid, algorithm, private_key, public_key, signs_at) values(?,?,?,?,?)")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(key.KID, key.Algorithm, key.PrivateKey, key.PublicKey, key.SignsAt); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//Latest retorna a chave mais nova que ainda não foi aposentada, que assina os tokens a partir de signs_at
+func (s SigningKeys) Latest() (models.SigningKey, error) {
+	keys, err := s.search("select kid, algorithm, private_key, public_key, created_at, signs_at, retires_at from signing_keys where retires_at is null order by created_at desc limit 1")
+	if err != nil || len(keys) == 0 {
+		return models.SigningKey{}, err
+	}
+
+	return keys[0], nil
+}
+
+//SearchActive retorna todas as chaves que ainda podem verificar tokens, incluindo as que estão na janela de sobreposição
+func (s SigningKeys) SearchActive() ([]models.SigningKey, error) {
+	return s.search("select kid, algorithm, private_key, public_key, created_at, signs_at, retires_at from signing_keys where retires_at is null or retires_at > ? order by created_at desc", time.Now())
+}
+
+//RetireOthers aposenta todas as chaves exceto a informada; elas continuam assinando até a nova começar e
+//verificando tokens até retiresAt
+func (s SigningKeys) RetireOthers(kid string, retiresAt time.Time) error {
+	sql, err := s.db.Prepare("update signing_keys set retires_at = ? where kid <> ? and retires_at is null")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(retiresAt, kid); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func (s SigningKeys) DeleteRetired() error {
+	_, err := s.db.Exec("delete from signing_keys where retires_at < ?", time.Now())
+	return err
+}
+
+func (s SigningKeys) search(query string, args ...interface{}) ([]models.SigningKey, error) {
+	rows, err := s.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []models.SigningKey
+
+	for rows.Next() {
+		var key models.SigningKey
+		var retiresAt sql.NullTime
+		if err = rows.Scan(
+			&key.KID,
+			&key.Algorithm,
+			&key.PrivateKey,
+			&key.PublicKey,
+			&key.CreatedAt,
+			&key.SignsAt,
+			&retiresAt,
+		); err != nil {
+			return nil, err
+		}
+
+		if retiresAt.Valid {
+			key.RetiresAt = &retiresAt.Time
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
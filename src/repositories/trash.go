@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"database/sql"
+	"time"
+)
+
+type Trash struct {
+	db *sql.DB
+}
+
+func NewTrashRep(db *sql.DB) *Trash {
+	return &Trash{db}
+}
+
+//Purge apaga de vez as publicações e os usuários que foram para a lixeira antes de before.
+//Apagar um usuário leva junto, pelo ON DELETE CASCADE, tudo o que é dele. Retorna quantas linhas foram apagadas.
+func (t Trash) Purge(before time.Time) (int64, error) {
+	var purged int64
+
+	for _, query := range []string{
+		"delete from posts where deleted_at < ?",
+		"delete from users where deleted_at < ?",
+	} {
+		result, err := t.db.Exec(query, before)
+		if err != nil {
+			return purged, err
+		}
+
+		affected, err := result.RowsAffected()
+		if err != nil {
+			return purged, err
+		}
+		purged += affected
+	}
+
+	return purged, nil
+}
@@ -0,0 +1,133 @@
+package repositories
+
+import (
+	"api/src/models"
+	"database/sql"
+)
+
+type TwoFactor struct {
+	db *sql.DB
+}
+
+func NewTwoFactorRep(db *sql.DB) *TwoFactor {
+	return &TwoFactor{db}
+}
+
+func (t TwoFactor) Get(userID uint64) (models.TwoFactorSettings, error) {
+	sql, err := t.db.Query("select coalesce(totp_secret, ''), totp_enabled, totp_last_step from users where id = ?", userID)
+	if err != nil {
+		return models.TwoFactorSettings{}, err
+	}
+	defer sql.Close()
+
+	var settings models.TwoFactorSettings
+	if sql.Next() {
+		if err = sql.Scan(&settings.Secret, &settings.Enabled, &settings.LastStep); err != nil {
+			return models.TwoFactorSettings{}, err
+		}
+	}
+
+	return settings, nil
+}
+
+//SetPendingSecret guarda o segredo de um cadastro que ainda não foi confirmado com um código
+func (t TwoFactor) SetPendingSecret(userID uint64, secret string) error {
+	sql, err := t.db.Prepare("update users set totp_secret = ?, totp_enabled = false, totp_last_step = 0 where id = ? and totp_enabled = false")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _, err = sql.Exec(secret, userID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+//Enable ativa o 2FA e troca os códigos de recuperação numa única transação. Retorna false se o segredo
+//conferido já não é o pendente, porque outro cadastro foi iniciado enquanto o código era validado
+func (t TwoFactor) Enable(userID uint64, secret string, step int64, codeHashes []string) (bool, error) {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(
+		"update users set totp_enabled = true, totp_last_step = ? where id = ? and totp_secret = ? and totp_enabled = false",
+		step, userID, secret,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected != 1 {
+		return false, nil
+	}
+
+	if _, err = tx.Exec("delete from recovery_codes where user_id = ?", userID); err != nil {
+		return false, err
+	}
+
+	for _, codeHash := range codeHashes {
+		if _, err = tx.Exec("insert into recovery_codes (user_id, code_hash) values(?,?)", userID, codeHash); err != nil {
+			return false, err
+		}
+	}
+
+	return true, tx.Commit()
+}
+
+func (t TwoFactor) Disable(userID uint64) error {
+	tx, err := t.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err = tx.Exec("update users set totp_secret = null, totp_enabled = false, totp_last_step = 0 where id = ?", userID); err != nil {
+		return err
+	}
+
+	if _, err = tx.Exec("delete from recovery_codes where user_id = ?", userID); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+//UseStep registra o passo do último código aceito; retorna false se um código desse passo (ou posterior) já foi usado
+func (t TwoFactor) UseStep(userID uint64, step int64) (bool, error) {
+	result, err := t.db.Exec("update users set totp_last_step = ? where id = ? and totp_last_step < ?", step, userID, step)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+//UseRecoveryCode consome um código de recuperação; retorna false se ele não existe ou já foi usado
+func (t TwoFactor) UseRecoveryCode(userID uint64, codeHash string) (bool, error) {
+	result, err := t.db.Exec("update recovery_codes set used_at = current_timestamp where user_id = ? and code_hash = ? and used_at is null", userID, codeHash)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
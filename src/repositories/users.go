@@ -4,6 +4,7 @@ import (
 	"api/src/models"
 	"database/sql"
 	"fmt"
+	"time"
 )
 
 type Users struct {
@@ -37,7 +38,7 @@ func (u Users) Create(user models.User) (uint64, error) {
 func (u Users) Search(value string) ([]models.User, error) {
 	newValue := fmt.Sprintf("%%%s%%", value)
 
-	sql, err := u.db.Query("select id, name, nick, email, created_at from users where name LIKE ? or nick LIKE ?", newValue, newValue)
+	sql, err := u.db.Query("select id, name, nick, email, created_at, coalesce(avatar_key, ''), coalesce(banner_key, '') from users where (name LIKE ? or nick LIKE ?) and deleted_at is null", newValue, newValue)
 
 	if err != nil {
 		return nil, err
@@ -48,7 +49,7 @@ func (u Users) Search(value string) ([]models.User, error) {
 
 	for sql.Next(){
 		var user models.User
-		if err = sql.Scan(&user.ID, &user.Name, &user.Nick, &user.Email, &user.CreatedAt); err != nil {
+		if err = sql.Scan(&user.ID, &user.Name, &user.Nick, &user.Email, &user.CreatedAt, &user.AvatarKey, &user.BannerKey); err != nil {
 			return nil, err
 		}
 
@@ -59,71 +60,88 @@ func (u Users) Search(value string) ([]models.User, error) {
 }
 
 func (u Users) GetById(id uint64) (models.User, error) {
-	sql, err := u.db.Query("select id, name, nick, email, created_at from users where id = ?", id)
+	rows, err := u.db.Query("select id, name, nick, email, created_at, email_verified_at, coalesce(avatar_key, ''), coalesce(banner_key, '') from users where id = ? and deleted_at is null", id)
 	if err != nil {
 		return models.User{}, err
 	}
-	defer sql.Close()
+	defer rows.Close()
 
 	var user models.User
 
-	if sql.Next() {
-		if err := sql.Scan(
+	if rows.Next() {
+		var emailVerifiedAt sql.NullTime
+		if err := rows.Scan(
 			&user.ID,
 			&user.Name,
 			&user.Nick,
 			&user.Email,
 			&user.CreatedAt,
+			&emailVerifiedAt,
+			&user.AvatarKey,
+			&user.BannerKey,
 		); err != nil {
 			return models.User{}, err
 		}
+
+		if emailVerifiedAt.Valid {
+			user.EmailVerifiedAt = &emailVerifiedAt.Time
+		}
 	}
 
 	return user, nil
 }
 
+//Update altera os dados do usuário; se o email mudar a confirmação dele é desfeita
 func (u Users) Update(id uint64, user models.User) error{
-	sql, err := u.db.Prepare("UPDATE users SET name = ?, email = ?, nick = ? where id = ?")
+	//o MySQL avalia o SET da esquerda para a direita, então a comparação usa o email antigo
+	sql, err := u.db.Prepare("UPDATE users SET email_verified_at = IF(email = ?, email_verified_at, NULL), name = ?, email = ?, nick = ? where id = ? and deleted_at is null")
 	if err != nil {
 		return err
 	}
 	defer sql.Close()
 
-	if _ , err := sql.Exec(user.Name, user.Email, user.Nick, id); err != nil {
+	if _ , err := sql.Exec(user.Email, user.Name, user.Email, user.Nick, id); err != nil {
 		return err
 	}
 	return nil
 }
 
-func (u Users) Delete(id uint64) error{
-	sql, err := u.db.Prepare("DELETE from users where id = ?")
+//Delete manda a conta para a lixeira guardando quem a excluiu, já que só a própria conta pode se restaurar
+func (u Users) Delete(id, deletedBy uint64) error{
+	sql, err := u.db.Prepare("UPDATE users SET deleted_at = current_timestamp, deleted_by = ? where id = ? and deleted_at is null")
 	if err != nil {
 		return err
 	}
 	defer sql.Close()
 
-	if _ , err := sql.Exec(id); err != nil {
+	if _ , err := sql.Exec(deletedBy, id); err != nil {
 		return err
 	}
 	return nil
 }
 
 func (u Users) SearchByEmail(email string) (models.User, error) {
-	sql, err := u.db.Query("SELECT id, password from users where email = ?", email)
+	rows, err := u.db.Query("SELECT id, password, email_verified_at from users where email = ? and deleted_at is null", email)
 	if err != nil {
 		return models.User{}, err
 	}
-	defer sql.Close()
+	defer rows.Close()
 
 	var user models.User
 
-	if sql.Next() {
-		if err := sql.Scan(
+	if rows.Next() {
+		var emailVerifiedAt sql.NullTime
+		if err := rows.Scan(
 			&user.ID,
 			&user.Password,
+			&emailVerifiedAt,
 		); err != nil {
 			return models.User{}, err
 		}
+
+		if emailVerifiedAt.Valid {
+			user.EmailVerifiedAt = &emailVerifiedAt.Time
+		}
 	}
 
 	return user, nil
@@ -158,7 +176,7 @@ func (u Users) StopFollowing(userID, followerID uint64) error{
 }
 
 func (u Users) GetFollowersById(userID uint64) ([]models.User, error){
-	sql, err := u.db.Query("select u.id, u.name, u.nick, u.email, u.created_at from users u inner join followers f on u.id = f.follower_id where f.user_id = ?", userID)
+	sql, err := u.db.Query("select u.id, u.name, u.nick, u.email, u.created_at, coalesce(u.avatar_key, ''), coalesce(u.banner_key, '') from users u inner join followers f on u.id = f.follower_id where f.user_id = ? and u.deleted_at is null", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -168,7 +186,7 @@ func (u Users) GetFollowersById(userID uint64) ([]models.User, error){
 
 	for sql.Next(){
 		var user models.User
-		if err = sql.Scan(&user.ID, &user.Name, &user.Nick, &user.Email, &user.CreatedAt); err != nil {
+		if err = sql.Scan(&user.ID, &user.Name, &user.Nick, &user.Email, &user.CreatedAt, &user.AvatarKey, &user.BannerKey); err != nil {
 			return nil, err
 		}
 
@@ -179,7 +197,7 @@ func (u Users) GetFollowersById(userID uint64) ([]models.User, error){
 }
 
 func (u Users) GetFollowing(userID uint64) ([]models.User, error){
-	sql, err := u.db.Query("select u.id, u.name, u.nick, u.email, u.created_at from users u inner join followers f on u.id = f.user_id where f.follower_id = ?", userID)
+	sql, err := u.db.Query("select u.id, u.name, u.nick, u.email, u.created_at, coalesce(u.avatar_key, ''), coalesce(u.banner_key, '') from users u inner join followers f on u.id = f.user_id where f.follower_id = ? and u.deleted_at is null", userID)
 	if err != nil {
 		return nil, err
 	}
@@ -189,7 +207,7 @@ func (u Users) GetFollowing(userID uint64) ([]models.User, error){
 
 	for sql.Next(){
 		var user models.User
-		if err = sql.Scan(&user.ID, &user.Name, &user.Nick, &user.Email, &user.CreatedAt); err != nil {
+		if err = sql.Scan(&user.ID, &user.Name, &user.Nick, &user.Email, &user.CreatedAt, &user.AvatarKey, &user.BannerKey); err != nil {
 			return nil, err
 		}
 
@@ -200,7 +218,7 @@ func (u Users) GetFollowing(userID uint64) ([]models.User, error){
 }
 
 func (u Users) GetCurrentPassword(userID uint64) (string, error){
-	sql, err := u.db.Query("select password from users where id = ?", userID)
+	sql, err := u.db.Query("select password from users where id = ? and deleted_at is null", userID)
 	if err != nil {
 		return "", err
 	}
@@ -217,7 +235,7 @@ func (u Users) GetCurrentPassword(userID uint64) (string, error){
 }
 
 func (u Users) UpdatePassword(userID uint64, newPassword string) error{
-	sql, err := u.db.Prepare("UPDATE users SET password = ? WHERE id = ?")
+	sql, err := u.db.Prepare("UPDATE users SET password = ? WHERE id = ? and deleted_at is null")
 	if err != nil {
 		return err
 	}
@@ -227,4 +245,138 @@ func (u Users) UpdatePassword(userID uint64, newPassword string) error{
 		return err
 	}
 	return nil
-}
\ No newline at end of file
+}
+//RevokeTokens invalida todos os access tokens emitidos para o usuário até agora
+func (u Users) RevokeTokens(userID uint64) error{
+	sql, err := u.db.Prepare("UPDATE users SET tokens_revoked_at = current_timestamp WHERE id = ?")
+	if err != nil {
+		return err
+	}
+	defer sql.Close()
+
+	if _ , err := sql.Exec(userID); err != nil {
+		return err
+	}
+	return nil
+}
+
+//MarkEmailVerified confirma o email do usuário, desde que ele ainda seja o mesmo para o qual o token foi enviado
+func (u Users) MarkEmailVerified(userID uint64, email string) (bool, error){
+	sql, err := u.db.Prepare("UPDATE users SET email_verified_at = current_timestamp WHERE id = ? and email = ?")
+	if err != nil {
+		return false, err
+	}
+	defer sql.Close()
+
+	result, err := sql.Exec(userID, email)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+//SearchDeletedByEmail busca a conta com o email que foi para a lixeira por último, depois de since, para que ela possa se restaurar
+func (u Users) SearchDeletedByEmail(email string, since time.Time) (models.User, error) {
+	rows, err := u.db.Query(
+		"SELECT id, password, coalesce(deleted_by, 0) from users where email = ? and deleted_at > ? order by deleted_at desc limit 1",
+		email, since,
+	)
+	if err != nil {
+		return models.User{}, err
+	}
+	defer rows.Close()
+
+	var user models.User
+
+	if rows.Next() {
+		if err := rows.Scan(&user.ID, &user.Password, &user.DeletedBy); err != nil {
+			return models.User{}, err
+		}
+	}
+
+	return user, nil
+}
+
+//Restore tira a conta da lixeira; retorna false se outra conta ativa já usa o mesmo email. A conferência fica no
+//próprio UPDATE para que um cadastro feito entre a busca e a restauração não deixe duas contas ativas com o email.
+//O MySQL não deixa a subconsulta ler a tabela que está sendo alterada, por isso ela passa por uma tabela derivada.
+func (u Users) Restore(id uint64) (bool, error) {
+	result, err := u.db.Exec(
+		`UPDATE users SET deleted_at = null, deleted_by = null WHERE id = ? and deleted_at is not null and not exists (
+			select 1 from (select id, email from users where deleted_at is null) active where active.email = users.email and active.id <> users.id
+		)`,
+		id,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected == 1, nil
+}
+
+//SetAvatar troca a chave do avatar e retorna a anterior, para que as versões antigas sejam apagadas do armazenamento
+func (u Users) SetAvatar(userID uint64, key string) (string, error) {
+	return u.replaceImage("avatar_key", userID, key)
+}
+
+func (u Users) SetBanner(userID uint64, key string) (string, error) {
+	return u.replaceImage("banner_key", userID, key)
+}
+
+//replaceImage troca a chave na coluna informada, que vem sempre do código e nunca da requisição
+func (u Users) replaceImage(column string, userID uint64, key string) (string, error) {
+	tx, err := u.db.Begin()
+	if err != nil {
+		return "", err
+	}
+	defer tx.Rollback()
+
+	var previous sql.NullString
+	if err = tx.QueryRow("select "+column+" from users where id = ? for update", userID).Scan(&previous); err != nil {
+		return "", err
+	}
+
+	if _, err = tx.Exec("update users set "+column+" = ? where id = ?", key, userID); err != nil {
+		return "", err
+	}
+
+	return previous.String, tx.Commit()
+}
+
+//SearchPurgeableImages lista as chaves de todas as versões dos avatares e banners dos usuários que a limpeza da lixeira vai apagar
+func (u Users) SearchPurgeableImages(before time.Time) ([]string, error) {
+	rows, err := u.db.Query("select coalesce(avatar_key, ''), coalesce(banner_key, '') from users where deleted_at < ?", before)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var keys []string
+
+	for rows.Next() {
+		var user models.User
+		if err = rows.Scan(&user.AvatarKey, &user.BannerKey); err != nil {
+			return nil, err
+		}
+
+		if user.AvatarKey != "" {
+			keys = append(keys, models.VariantKeys(user.AvatarKey, models.AvatarSizes)...)
+		}
+		if user.BannerKey != "" {
+			keys = append(keys, models.VariantKeys(user.BannerKey, models.BannerSizes)...)
+		}
+	}
+
+	return keys, rows.Err()
+}
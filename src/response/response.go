@@ -2,6 +2,7 @@ package response
 
 import (
 	"encoding/json"
+	"errors"
 	"log"
 	"net/http"
 )
@@ -19,6 +20,19 @@ func JSON(w http.ResponseWriter, statusCode int, dados interface{}) {
 }
 
 func Erro(w http.ResponseWriter, statusCode int, erro error){
+	//erros de validação que apontam os campos com problema também devolvem os detalhes de cada campo
+	var campos interface{ Fields() map[string][]string }
+	if errors.As(erro, &campos) {
+		JSON(w, statusCode, struct {
+			Erro   string              `json:"erro"`
+			Campos map[string][]string `json:"campos"`
+		}{
+			Erro:   erro.Error(),
+			Campos: campos.Fields(),
+		})
+		return
+	}
+
 	JSON(w, statusCode, struct {
 		Erro string `json:"erro"`
 	}{
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var adminRoutes = []Route{
+	{
+		URI:      "/users/{userId}/Roles/{role}",
+		Method:   http.MethodPut,
+		Funcao:   controllers.AssignRole,
+		NeedAuth: true,
+		Roles:    []string{models.RoleAdmin},
+	},
+	{
+		URI:      "/users/{userId}/Roles/{role}",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.RemoveRole,
+		NeedAuth: true,
+		Roles:    []string{models.RoleAdmin},
+	},
+	{
+		URI:      "/audit",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetAuditLogs,
+		NeedAuth: true,
+		Roles:    []string{models.RoleAdmin},
+	},
+}
@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var attachmentRoutes = []Route{
+	{
+		URI:      "/Posts/{postID}/Attachments",
+		Method:   http.MethodPost,
+		Funcao:   controllers.UploadAttachments,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+	{
+		URI:      "/Posts/{postID}/Attachments/{attachmentID}",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.DeleteAttachment,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+	{
+		URI:      "/media/{key:.+}",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetMedia,
+		NeedAuth: false,
+	},
+}
@@ -0,0 +1,31 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var bookmarkRoutes = []Route{
+	{
+		URI:      "/Posts/{postID}/Bookmark",
+		Method:   http.MethodPost,
+		Funcao:   controllers.BookmarkPost,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+	{
+		URI:      "/Posts/{postID}/Bookmark",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.UnbookmarkPost,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+	{
+		URI:      "/bookmarks",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetBookmarks,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
+	},
+}
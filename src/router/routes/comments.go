@@ -0,0 +1,38 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var commentRoutes = []Route{
+	{
+		URI:      "/Posts/{idPost}/Comments",
+		Method:   http.MethodPost,
+		Funcao:   controllers.NewComment,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+	{
+		URI:      "/Posts/{idPost}/Comments",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetComments,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
+	},
+	{
+		URI:      "/Posts/{idPost}/Comments/{commentId}",
+		Method:   http.MethodPut,
+		Funcao:   controllers.UpdateComment,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+	{
+		URI:      "/Posts/{idPost}/Comments/{commentId}",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.DeleteComment,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var hashtagRoutes = []Route{
+	{
+		URI:      "/hashtags/trending",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetTrendingHashtags,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
+	},
+	{
+		URI:      "/hashtags/{tag}/Posts",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetHashtagPosts,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
+	},
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var keysRoutes = []Route{
+	{
+		URI:      "/.well-known/jwks.json",
+		Method:   http.MethodGet,
+		Funcao:   controllers.JWKS,
+		NeedAuth: false,
+	},
+}
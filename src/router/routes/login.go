@@ -5,10 +5,65 @@ import (
 	"net/http"
 )
 
-var login = Route{
-
-	URI:      "/login",
-	Method:   http.MethodPost,
-	Funcao:   controllers.Login,
-	NeedAuth: false,
+var loginRoutes = []Route{
+	{
+		URI:      "/login",
+		Method:   http.MethodPost,
+		Funcao:   controllers.Login,
+		NeedAuth: false,
+	},
+	{
+		URI:      "/login/refresh",
+		Method:   http.MethodPost,
+		Funcao:   controllers.RefreshToken,
+		NeedAuth: false,
+	},
+	{
+		URI:      "/login/magic",
+		Method:   http.MethodPost,
+		Funcao:   controllers.SendMagicLink,
+		NeedAuth: false,
+	},
+	{
+		URI:      "/login/magic/{token}",
+		Method:   http.MethodGet,
+		Funcao:   controllers.LoginMagicLink,
+		NeedAuth: false,
+	},
+	{
+		URI:      "/login/2fa",
+		Method:   http.MethodPost,
+		Funcao:   controllers.LoginTwoFactor,
+		NeedAuth: false,
+	},
+	{
+		URI:      "/2fa/enroll",
+		Method:   http.MethodPost,
+		Funcao:   controllers.EnrollTwoFactor,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/2fa/confirm",
+		Method:   http.MethodPost,
+		Funcao:   controllers.ConfirmTwoFactor,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/2fa/disable",
+		Method:   http.MethodPost,
+		Funcao:   controllers.DisableTwoFactor,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/logout",
+		Method:   http.MethodPost,
+		Funcao:   controllers.Logout,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/logout/all",
+		Method:   http.MethodPost,
+		Funcao:   controllers.LogoutAll,
+		NeedAuth: true,
+	},
 }
\ No newline at end of file
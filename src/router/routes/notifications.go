@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var notificationRoutes = []Route{
+	{
+		URI:      "/notifications",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetNotifications,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/notifications/read",
+		Method:   http.MethodPost,
+		Funcao:   controllers.ReadNotifications,
+		NeedAuth: true,
+	},
+}
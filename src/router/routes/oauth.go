@@ -0,0 +1,58 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+//as rotas de autorização não declaram escopos: só o próprio usuário, com o login normal, autoriza aplicativos
+var oauthRoutes = []Route{
+	{
+		URI:      "/oauth/clients",
+		Method:   http.MethodPost,
+		Funcao:   controllers.CreateOAuthClient,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/oauth/clients",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetOAuthClients,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/oauth/clients/{clientId}",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.DeleteOAuthClient,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/oauth/grants",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetOAuthGrants,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/oauth/grants/{clientId}",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.DeleteOAuthGrant,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/oauth/authorize",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetAuthorize,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/oauth/authorize",
+		Method:   http.MethodPost,
+		Funcao:   controllers.PostAuthorize,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/oauth/token",
+		Method:   http.MethodPost,
+		Funcao:   controllers.Token,
+		NeedAuth: false,
+	},
+}
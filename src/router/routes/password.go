@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var passwordRoutes = []Route{
+	{
+		URI:      "/password/forgot",
+		Method:   http.MethodPost,
+		Funcao:   controllers.ForgotPassword,
+		NeedAuth: false,
+	},
+	{
+		URI:      "/password/reset",
+		Method:   http.MethodPost,
+		Funcao:   controllers.ResetPassword,
+		NeedAuth: false,
+	},
+}
@@ -2,6 +2,7 @@ package routes
 
 import (
 	"api/src/controllers"
+	"api/src/models"
 	"net/http"
 )
 
@@ -11,47 +12,83 @@ var postsRoutes = []Route{
 		Method:   http.MethodPost,
 		Funcao:   controllers.NewPost,
 		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
 	},
 	{
 		URI:      "/Posts",
 		Method:   http.MethodGet,
 		Funcao:   controllers.GetPosts,
 		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
 	},
 	{
 		URI:      "/Posts/{idPost}",
 		Method:   http.MethodGet,
 		Funcao:   controllers.GetOnePost,
 		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
 	},
 	{
 		URI:      "/Posts/{idPost}",
 		Method:   http.MethodPut,
 		Funcao:   controllers.UpdatePost,
 		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
 	},
 	{
 		URI:      "/Posts/{idPost}",
 		Method:   http.MethodDelete,
 		Funcao:   controllers.DeletePost,
 		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
 	},
 	{
 		URI:      "/Users/{userID}/Posts",
 		Method:   http.MethodGet,
 		Funcao:   controllers.GetPostsByUser,
 		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
 	},
 	{
 		URI:      "/Posts/{postID}/Like",
 		Method:   http.MethodPost,
 		Funcao:   controllers.LikePost,
 		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
 	},
 	{
 		URI:      "/Posts/{postID}/Unlike",
 		Method:   http.MethodPost,
 		Funcao:   controllers.UnlikePost,
 		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
 	},
-}
\ No newline at end of file
+	{
+		URI:      "/Posts/{postID}/Likes",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetPostLikes,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
+	},
+	{
+		URI:      "/Posts/{postID}/Repost",
+		Method:   http.MethodPost,
+		Funcao:   controllers.Repost,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+	{
+		URI:      "/Posts/{postID}/Repost",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.UndoRepost,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+	{
+		URI:      "/Posts/{postID}/Quote",
+		Method:   http.MethodPost,
+		Funcao:   controllers.QuotePost,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+}
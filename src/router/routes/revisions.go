@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var revisionRoutes = []Route{
+	{
+		URI:      "/Posts/{idPost}/Revisions",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetPostRevisions,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
+	},
+	{
+		URI:      "/Posts/{idPost}/Revisions/Diff",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetRevisionDiff,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
+	},
+}
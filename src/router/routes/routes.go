@@ -12,19 +12,43 @@ type Route struct {
 	Method   string              `json:"method"`
 	Funcao   func(http.ResponseWriter, *http.Request) 
 	NeedAuth bool              `json:"need_auth"`
+	//Roles, quando preenchido, restringe a rota aos usuários com pelo menos um desses papéis
+	Roles    []string            `json:"roles"`
+	//Scopes são os escopos exigidos de tokens com escopo (personal access tokens e tokens OAuth). Sem escopos
+	//declarados a rota só aceita o login normal: esses tokens recebem 403 nela, de propósito, para que uma rota
+	//nova nunca fique aberta a eles por esquecimento
+	Scopes   []string            `json:"scopes"`
 }
 
 func RouteConfig(r *mux.Router) *mux.Router {
 	routes := usersRoute
-	routes = append(routes, login)
+	routes = append(routes, loginRoutes...)
 	routes = append(routes, postsRoutes...)
+	routes = append(routes, keysRoutes...)
+	routes = append(routes, adminRoutes...)
+	routes = append(routes, passwordRoutes...)
+	routes = append(routes, verificationRoutes...)
+	routes = append(routes, tokenRoutes...)
+	routes = append(routes, oauthRoutes...)
+	routes = append(routes, sessionRoutes...)
+	routes = append(routes, commentRoutes...)
+	routes = append(routes, hashtagRoutes...)
+	routes = append(routes, notificationRoutes...)
+	routes = append(routes, bookmarkRoutes...)
+	routes = append(routes, revisionRoutes...)
+	routes = append(routes, trashRoutes...)
+	routes = append(routes, attachmentRoutes...)
 
 	for _, route := range routes {
+		handler := route.Funcao
 
+		if len(route.Roles) > 0 {
+			handler = middlewares.Authorize(route.Roles, handler)
+		}
 		if route.NeedAuth {
-			r.HandleFunc(route.URI, middlewares.Logger(middlewares.Authenticate(route.Funcao))).Methods(route.Method)
+			handler = middlewares.Authenticate(middlewares.RequireScopes(route.Scopes, handler))
 		}
-		r.HandleFunc(route.URI, middlewares.Logger(route.Funcao)).Methods(route.Method)
+		r.HandleFunc(route.URI, middlewares.Logger(handler)).Methods(route.Method)
 
 	}
 
@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var sessionRoutes = []Route{
+	{
+		URI:      "/sessions",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetSessions,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/sessions/{sessionId}",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.DeleteSession,
+		NeedAuth: true,
+	},
+}
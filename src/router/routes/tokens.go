@@ -0,0 +1,27 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var tokenRoutes = []Route{
+	{
+		URI:      "/tokens",
+		Method:   http.MethodPost,
+		Funcao:   controllers.CreatePersonalAccessToken,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/tokens",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetPersonalAccessTokens,
+		NeedAuth: true,
+	},
+	{
+		URI:      "/tokens/{tokenId}",
+		Method:   http.MethodDelete,
+		Funcao:   controllers.RevokePersonalAccessToken,
+		NeedAuth: true,
+	},
+}
@@ -0,0 +1,24 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"api/src/models"
+	"net/http"
+)
+
+var trashRoutes = []Route{
+	{
+		URI:      "/trash",
+		Method:   http.MethodGet,
+		Funcao:   controllers.GetTrash,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsRead},
+	},
+	{
+		URI:      "/Posts/{idPost}/Restore",
+		Method:   http.MethodPost,
+		Funcao:   controllers.RestorePost,
+		NeedAuth: true,
+		Scopes:   []string{models.ScopePostsWrite},
+	},
+}
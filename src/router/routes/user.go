@@ -2,6 +2,7 @@ package routes
 
 import (
 	"api/src/controllers"
+	"api/src/models"
 	"net/http"
 )
 
@@ -17,18 +18,27 @@ var usersRoute = []Route{
 		Method: http.MethodGet,
 		Funcao: controllers.GetAllUsers,
 		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersRead},
+	},
+	{
+		URI:    "/users/restore",
+		Method: http.MethodPost,
+		Funcao: controllers.RestoreUser,
+		NeedAuth: false,
 	},
 	{
 		URI:    "/users/{userId}",
 		Method: http.MethodGet,
 		Funcao: controllers.GetOneUser,
 		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersRead},
 	},
 	{
 		URI:    "/users/{userId}",
 		Method: http.MethodPut,
 		Funcao: controllers.UpdateUser,
 		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersWrite},
 	},
 	{
 		URI:    "/users/{userId}",
@@ -36,29 +46,68 @@ var usersRoute = []Route{
 		Funcao: controllers.DeleteUser,
 		NeedAuth: true,
 	},
+	{
+		URI:    "/users/{userId}/Avatar",
+		Method: http.MethodPut,
+		Funcao: controllers.UpdateAvatar,
+		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersWrite},
+	},
+	{
+		URI:    "/users/{userId}/Banner",
+		Method: http.MethodPut,
+		Funcao: controllers.UpdateBanner,
+		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersWrite},
+	},
 	{
 		URI:    "/users/{userId}/Follow",
 		Method: http.MethodPost,
 		Funcao: controllers.NewFollow,
 		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersWrite},
 	},
 	{
 		URI:    "/users/{userId}/StopFollowing",
 		Method: http.MethodPost,
 		Funcao: controllers.StopFollowing,
 		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersWrite},
+	},
+	{
+		URI:    "/users/{userId}/Block",
+		Method: http.MethodPost,
+		Funcao: controllers.BlockUser,
+		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersWrite},
+	},
+	{
+		URI:    "/users/{userId}/Unblock",
+		Method: http.MethodPost,
+		Funcao: controllers.UnblockUser,
+		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersWrite},
+	},
+	{
+		URI:    "/users/{userId}/Mentions",
+		Method: http.MethodGet,
+		Funcao: controllers.GetUserMentions,
+		NeedAuth: true,
+		Scopes: []string{models.ScopePostsRead},
 	},
 	{
 		URI:    "/users/{userId}/Followers",
 		Method: http.MethodGet,
 		Funcao: controllers.Followers,
 		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersRead},
 	},
 	{
 		URI:    "/users/{userId}/Following",
 		Method: http.MethodGet,
 		Funcao: controllers.Following,
 		NeedAuth: true,
+		Scopes: []string{models.ScopeUsersRead},
 	},
 	{
 		URI:    "/users/{userId}/NewPassword",
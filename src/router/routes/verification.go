@@ -0,0 +1,21 @@
+package routes
+
+import (
+	"api/src/controllers"
+	"net/http"
+)
+
+var verificationRoutes = []Route{
+	{
+		URI:      "/verify-email",
+		Method:   http.MethodGet,
+		Funcao:   controllers.VerifyEmail,
+		NeedAuth: false,
+	},
+	{
+		URI:      "/verify-email/resend",
+		Method:   http.MethodPost,
+		Funcao:   controllers.ResendVerificationEmail,
+		NeedAuth: true,
+	},
+}
@@ -0,0 +1,106 @@
+package scheduler
+
+import (
+	"api/src/config"
+	"api/src/db"
+	"api/src/repositories"
+	"api/src/storage"
+	"database/sql"
+	"log"
+	"time"
+)
+
+const (
+	publishLock = "devbook_scheduled_posts"
+	purgeLock   = "devbook_trash_purge"
+)
+
+//purgeInterval é de quanto em quanto tempo a lixeira é limpa; um atraso de uma hora não faz diferença num prazo de dias
+const purgeInterval = time.Hour
+
+//Start roda em segundo plano as tarefas periódicas da API: publicar as publicações agendadas cujo horário
+//já chegou e limpar a lixeira. Com várias réplicas da API só quem pegar o lock roda cada tarefa em cada rodada.
+func Start() {
+	every(config.PublishInterval, publishLock, publishDue)
+	every(purgeInterval, purgeLock, purgeTrash)
+}
+
+func every(interval time.Duration, lock string, task func(db *sql.DB) error) {
+	go func() {
+		for range time.Tick(interval) {
+			if err := runLocked(lock, task); err != nil {
+				log.Printf("erro na tarefa %s: %v", lock, err)
+			}
+		}
+	}()
+}
+
+func runLocked(lock string, task func(db *sql.DB) error) error {
+	db, err := db.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	locks := repositories.NewLockRep(db)
+	acquired, err := locks.Acquire(lock)
+	if err != nil || !acquired {
+		return err
+	}
+
+	err = task(db)
+	if releaseErr := locks.Release(lock); err == nil {
+		err = releaseErr
+	}
+
+	return err
+}
+
+func publishDue(db *sql.DB) error {
+	published, err := repositories.NewPostRep(db).PublishDue()
+	if err != nil {
+		return err
+	}
+
+	if published > 0 {
+		log.Printf("%d publicações agendadas publicadas", published)
+	}
+
+	return nil
+}
+
+//purgeTrash apaga primeiro os arquivos dos anexos e das imagens de perfil, que o banco não alcança, e depois as linhas
+func purgeTrash(db *sql.DB) error {
+	before := time.Now().Add(-config.TrashRetention)
+
+	attachments, err := repositories.NewAttachmentRep(db).SearchPurgeable(before)
+	if err != nil {
+		return err
+	}
+
+	keys, err := repositories.NewUserRep(db).SearchPurgeableImages(before)
+	if err != nil {
+		return err
+	}
+
+	for _, attachment := range attachments {
+		keys = append(keys, attachment.Key, attachment.ThumbnailKey)
+	}
+
+	for _, key := range keys {
+		if err = storage.Default.Delete(key); err != nil {
+			return err
+		}
+	}
+
+	purged, err := repositories.NewTrashRep(db).Purge(before)
+	if err != nil {
+		return err
+	}
+
+	if purged > 0 {
+		log.Printf("%d itens apagados da lixeira", purged)
+	}
+
+	return nil
+}
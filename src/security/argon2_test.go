@@ -0,0 +1,115 @@
+package security
+
+import (
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+//parâmetros pequenos para os testes não gastarem 64 MiB a cada hash
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 8, KeyLength: 16}
+
+func TestDecodeArgon2id(t *testing.T) {
+	hash, err := hashArgon2id("senha", testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		hash    string
+		want    Argon2Params
+		wantErr bool
+	}{
+		{"hash gerado", string(hash), testArgon2Params, false},
+		{
+			"parâmetros do RFC 9106",
+			"$argon2id$v=19$m=65536,t=3,p=2$c2FsdHNhbHRzYWx0c2FsdA$MTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTY3ODkwMTI",
+			Argon2Params{Memory: 65536, Iterations: 3, Parallelism: 2, SaltLength: 16, KeyLength: 32},
+			false,
+		},
+		{"outra variante", "$argon2i$v=19$m=64,t=1,p=1$c2FsdA$a2V5", Argon2Params{}, true},
+		{"outra versão", "$argon2id$v=16$m=64,t=1,p=1$c2FsdA$a2V5", Argon2Params{}, true},
+		{"parâmetros faltando", "$argon2id$v=19$m=64,t=1$c2FsdA$a2V5", Argon2Params{}, true},
+		{"salt inválido", "$argon2id$v=19$m=64,t=1,p=1$c2Fs!A$a2V5", Argon2Params{}, true},
+		{"chave inválida", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA$a2V5==", Argon2Params{}, true},
+		{"partes faltando", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA", Argon2Params{}, true},
+		{"bcrypt", "$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy", Argon2Params{}, true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			got, _, _, err := decodeArgon2id(test.hash)
+			if (err != nil) != test.wantErr {
+				t.Fatalf("decodeArgon2id() erro = %v, esperado erro: %v", err, test.wantErr)
+			}
+			if got != test.want {
+				t.Errorf("decodeArgon2id() = %+v, esperado %+v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestValidatePasswordArgon2id(t *testing.T) {
+	hash, err := hashArgon2id("senha correta", testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err = ValidatePassword("senha correta", string(hash)); err != nil {
+		t.Errorf("a senha correta foi recusada: %v", err)
+	}
+	if err = ValidatePassword("senha errada", string(hash)); err != errMismatchedPassword {
+		t.Errorf("a senha errada retornou %v, esperado %v", err, errMismatchedPassword)
+	}
+}
+
+func TestNeedsRehash(t *testing.T) {
+	current, err := hashArgon2id("senha", testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	moreMemory := testArgon2Params
+	moreMemory.Memory *= 2
+	outdated, err := hashArgon2id("senha", moreMemory)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	bcryptHash, err := bcrypt.GenerateFromPassword([]byte("senha"), bcrypt.MinCost)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	argon2Hashing := HashParams{Algorithm: AlgorithmArgon2id, Argon2: testArgon2Params, BcryptCost: bcrypt.MinCost}
+	bcryptHashing := HashParams{Algorithm: AlgorithmBcrypt, Argon2: testArgon2Params, BcryptCost: bcrypt.MinCost}
+	costlierBcrypt := HashParams{Algorithm: AlgorithmBcrypt, Argon2: testArgon2Params, BcryptCost: bcrypt.MinCost + 1}
+
+	tests := []struct {
+		name    string
+		hashing HashParams
+		hash    string
+		want    bool
+	}{
+		{"argon2id com os parâmetros atuais", argon2Hashing, string(current), false},
+		{"argon2id com outros parâmetros", argon2Hashing, string(outdated), true},
+		{"bcrypt com argon2id configurado", argon2Hashing, string(bcryptHash), true},
+		{"hash inválido com argon2id configurado", argon2Hashing, "inválido", true},
+		{"bcrypt com o custo atual", bcryptHashing, string(bcryptHash), false},
+		{"bcrypt com outro custo", costlierBcrypt, string(bcryptHash), true},
+		{"argon2id com bcrypt configurado", bcryptHashing, string(current), true},
+	}
+
+	previous := Hashing
+	defer func() { Hashing = previous }()
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			Hashing = test.hashing
+			if got := NeedsRehash(test.hash); got != test.want {
+				t.Errorf("NeedsRehash() = %v, esperado %v", got, test.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,80 @@
+package security
+
+import (
+	"bufio"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"strings"
+)
+
+//breachPrefixLength é o tamanho do prefixo do SHA-1 usado para agrupar os hashes, o mesmo da API do Have I Been Pwned
+const breachPrefixLength = 5
+
+//BreachList guarda os SHA-1 de senhas vazadas agrupados pelo prefixo, no modelo de k-anonimato: a consulta só
+//usa os 5 primeiros caracteres do hash e compara o restante localmente. Assim a lista pode ser trocada por
+//um serviço de consulta por prefixo sem que a senha, ou mesmo o hash completo, saia da API.
+type BreachList struct {
+	ranges map[string]map[string]struct{}
+}
+
+//LoadBreachList lê um arquivo no formato do Have I Been Pwned, com uma linha "SHA1:ocorrências" por senha.
+//Linhas sem a contagem também são aceitas.
+func LoadBreachList(path string) (*BreachList, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	list := &BreachList{ranges: map[string]map[string]struct{}{}}
+	scanner := bufio.NewScanner(file)
+	line := 0
+
+	for scanner.Scan() {
+		line++
+		hash := strings.TrimSpace(scanner.Text())
+		if index := strings.IndexByte(hash, ':'); index != -1 {
+			hash = hash[:index]
+		}
+
+		if hash == "" {
+			continue
+		}
+
+		if len(hash) != sha1.Size*2 {
+			return nil, fmt.Errorf("linha %d da lista de senhas vazadas não tem um SHA-1 válido", line)
+		}
+
+		list.add(strings.ToUpper(hash))
+	}
+
+	if err = scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (b *BreachList) add(hash string) {
+	prefix, suffix := hash[:breachPrefixLength], hash[breachPrefixLength:]
+	if b.ranges[prefix] == nil {
+		b.ranges[prefix] = map[string]struct{}{}
+	}
+	b.ranges[prefix][suffix] = struct{}{}
+}
+
+//Range retorna os sufixos dos hashes vazados que começam com o prefixo informado
+func (b *BreachList) Range(prefix string) map[string]struct{} {
+	return b.ranges[strings.ToUpper(prefix)]
+}
+
+//Contains indica se a senha está na lista de senhas vazadas
+func (b *BreachList) Contains(password string) bool {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	_, found := b.Range(hash[:breachPrefixLength])[hash[breachPrefixLength:]]
+	return found
+}
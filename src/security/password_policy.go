@@ -0,0 +1,129 @@
+package security
+
+import (
+	"api/src/config"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+//classes de caracteres que a política pode exigir
+const (
+	ClassLower  = "lower"
+	ClassUpper  = "upper"
+	ClassDigit  = "digit"
+	ClassSymbol = "symbol"
+)
+
+//PasswordPolicy são as regras que uma senha nova precisa seguir
+type PasswordPolicy struct {
+	MinLength       int
+	RequiredClasses []string
+	//Breaches é a lista de senhas vazadas; sem ela a verificação de vazamentos é desligada
+	Breaches *BreachList
+}
+
+//Policy é a política aplicada em CheckPassword, montada a partir da configuração por Configure
+var Policy = PasswordPolicy{MinLength: 8}
+
+//FieldError descreve todos os problemas de validação de um campo do corpo da requisição
+type FieldError struct {
+	Field    string
+	Problems []string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("o campo %s é inválido: %s", e.Field, strings.Join(e.Problems, "; "))
+}
+
+//Fields permite que a resposta de erro mostre os problemas separados por campo
+func (e *FieldError) Fields() map[string][]string {
+	return map[string][]string{e.Field: e.Problems}
+}
+
+//Configure monta a política de senhas e os parâmetros de hash conforme a configuração e carrega a lista
+//de senhas vazadas, se houver uma
+func Configure() error {
+	if err := configureHashing(); err != nil {
+		return err
+	}
+
+	policy := PasswordPolicy{MinLength: config.PasswordMinLength, RequiredClasses: config.PasswordRequiredClasses}
+
+	for _, class := range policy.RequiredClasses {
+		if _, ok := classDescriptions[class]; !ok {
+			return fmt.Errorf("classe de caracteres desconhecida %s", class)
+		}
+	}
+
+	if config.PasswordBreachList != "" {
+		breaches, err := LoadBreachList(config.PasswordBreachList)
+		if err != nil {
+			return err
+		}
+		policy.Breaches = breaches
+	}
+
+	Policy = policy
+	return nil
+}
+
+//CheckPassword aplica a política atual à senha. field é o nome do campo no corpo da requisição e personal são
+//dados do usuário, como o nick e o email, que não podem ser usados como senha.
+func CheckPassword(field, password string, personal ...string) error {
+	return Policy.Check(field, password, personal...)
+}
+
+//Check retorna um *FieldError com todos os problemas encontrados, para que o usuário corrija tudo de uma vez
+func (p PasswordPolicy) Check(field, password string, personal ...string) error {
+	if password == "" {
+		return &FieldError{Field: field, Problems: []string{"a senha é obrigatória"}}
+	}
+
+	var problems []string
+
+	if utf8.RuneCountInString(password) < p.MinLength {
+		problems = append(problems, fmt.Sprintf("a senha deve ter pelo menos %d caracteres", p.MinLength))
+	}
+
+	for _, class := range p.RequiredClasses {
+		if strings.IndexFunc(password, classDescriptions[class].matches) == -1 {
+			problems = append(problems, fmt.Sprintf("a senha deve ter pelo menos um caractere %s", classDescriptions[class].name))
+		}
+	}
+
+	for _, value := range personal {
+		value = strings.TrimSpace(value)
+		if value != "" && strings.EqualFold(password, value) {
+			problems = append(problems, "a senha não pode ser igual ao seu nick ou email")
+			break
+		}
+	}
+
+	if p.Breaches != nil && p.Breaches.Contains(password) {
+		problems = append(problems, "esta senha apareceu em vazamentos de dados conhecidos, escolha outra")
+	}
+
+	if len(problems) > 0 {
+		return &FieldError{Field: field, Problems: problems}
+	}
+
+	return nil
+}
+
+type characterClass struct {
+	name    string
+	matches func(rune) bool
+}
+
+var classDescriptions = map[string]characterClass{
+	ClassLower:  {"minúsculo", unicode.IsLower},
+	ClassUpper:  {"maiúsculo", unicode.IsUpper},
+	ClassDigit:  {"numérico", unicode.IsDigit},
+	ClassSymbol: {"especial", isSymbol},
+}
+
+func isSymbol(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.IsSpace(r)
+}
@@ -1,11 +1,98 @@
 package security
 
-import "golang.org/x/crypto/bcrypt"
+import (
+	"api/src/config"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+//algoritmos de hash de senha suportados; o formato do hash guarda o algoritmo e os parâmetros usados,
+//então hashes antigos continuam sendo conferidos depois de uma troca de configuração
+const (
+	AlgorithmArgon2id = "argon2id"
+	AlgorithmBcrypt   = "bcrypt"
+)
+
+//HashParams são o algoritmo e os parâmetros usados para gerar os hashes novos
+type HashParams struct {
+	Algorithm  string
+	Argon2     Argon2Params
+	BcryptCost int
+}
+
+//Hashing é a configuração atual de hash de senha, montada a partir da configuração por Configure
+var Hashing = HashParams{
+	Algorithm:  AlgorithmArgon2id,
+	Argon2:     DefaultArgon2Params,
+	BcryptCost: bcrypt.DefaultCost,
+}
+
+var errMismatchedPassword = errors.New("senha incorreta")
 
 func Hash(password string) ([]byte, error) {
-	return bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	switch Hashing.Algorithm {
+	case AlgorithmArgon2id:
+		return hashArgon2id(password, Hashing.Argon2)
+	case AlgorithmBcrypt:
+		return bcrypt.GenerateFromPassword([]byte(password), Hashing.BcryptCost)
+	default:
+		return nil, fmt.Errorf("algoritmo de hash de senha não suportado %s", Hashing.Algorithm)
+	}
 }
 
-func ValidatePassword(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(password), []byte(hashedPassword))
-}
\ No newline at end of file
+//ValidatePassword confere a senha com o hash guardado, seja ele argon2id ou bcrypt
+func ValidatePassword(password, hash string) error {
+	if strings.HasPrefix(hash, "$argon2id$") {
+		return compareArgon2id(hash, password)
+	}
+
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+}
+
+//NeedsRehash indica se o hash foi gerado com outro algoritmo ou com parâmetros diferentes dos atuais,
+//caso em que ele deve ser refeito assim que a senha for conferida
+func NeedsRehash(hash string) bool {
+	switch Hashing.Algorithm {
+	case AlgorithmArgon2id:
+		params, _, _, err := decodeArgon2id(hash)
+		return err != nil || params != Hashing.Argon2
+	case AlgorithmBcrypt:
+		cost, err := bcrypt.Cost([]byte(hash))
+		return err != nil || cost != Hashing.BcryptCost
+	default:
+		return false
+	}
+}
+
+func configureHashing() error {
+	hashing := HashParams{
+		Algorithm: config.PasswordHashAlgorithm,
+		Argon2: Argon2Params{
+			Memory:      config.Argon2Memory,
+			Iterations:  config.Argon2Iterations,
+			Parallelism: config.Argon2Parallelism,
+			SaltLength:  DefaultArgon2Params.SaltLength,
+			KeyLength:   DefaultArgon2Params.KeyLength,
+		},
+		BcryptCost: config.BcryptCost,
+	}
+
+	switch hashing.Algorithm {
+	case AlgorithmArgon2id:
+		if hashing.Argon2.Memory == 0 || hashing.Argon2.Iterations == 0 || hashing.Argon2.Parallelism == 0 {
+			return errors.New("parâmetros do argon2id inválidos")
+		}
+	case AlgorithmBcrypt:
+		if hashing.BcryptCost < bcrypt.MinCost || hashing.BcryptCost > bcrypt.MaxCost {
+			return fmt.Errorf("o custo do bcrypt deve ficar entre %d e %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	default:
+		return fmt.Errorf("algoritmo de hash de senha não suportado %s", hashing.Algorithm)
+	}
+
+	Hashing = hashing
+	return nil
+}
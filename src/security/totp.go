@@ -0,0 +1,95 @@
+package security
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//parâmetros do RFC 6238 aceitos por todos os aplicativos autenticadores
+const (
+	totpPeriod  = 30
+	totpDigits  = 6
+	totpModulus = 1000000
+)
+
+var base32NoPadding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+//NewTOTPSecret gera o segredo compartilhado com o aplicativo autenticador, em base32
+func NewTOTPSecret() (string, error) {
+	secret := make([]byte, 20)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return base32NoPadding.EncodeToString(secret), nil
+}
+
+//TOTPURI monta a URI otpauth:// que os aplicativos autenticadores leem pelo QR code
+func TOTPURI(issuer, account, secret string) string {
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(totpDigits))
+	params.Set("period", fmt.Sprint(totpPeriod))
+
+	return fmt.Sprintf("otpauth://totp/%s?%s", url.PathEscape(issuer+":"+account), params.Encode())
+}
+
+//ValidateTOTP confere o código aceitando um passo de diferença no relógio. Retorna o passo do código
+//para que quem chamar possa recusar um código que já foi usado.
+func ValidateTOTP(secret, code string, at time.Time) (int64, bool) {
+	code = strings.TrimSpace(code)
+	current := at.Unix() / totpPeriod
+
+	for _, step := range []int64{current - 1, current, current + 1} {
+		expected, err := totpCode(secret, step)
+		if err == nil && hmac.Equal([]byte(expected), []byte(code)) {
+			return step, true
+		}
+	}
+
+	return 0, false
+}
+
+//NewRecoveryCodes gera códigos de uso único para quando o usuário perder o aplicativo autenticador
+func NewRecoveryCodes(amount int) ([]string, error) {
+	codes := make([]string, amount)
+	for i := range codes {
+		buffer := make([]byte, 5)
+		if _, err := rand.Read(buffer); err != nil {
+			return nil, err
+		}
+
+		code := strings.ToLower(base32NoPadding.EncodeToString(buffer))
+		codes[i] = code[:4] + "-" + code[4:]
+	}
+
+	return codes, nil
+}
+
+func totpCode(secret string, step int64) (string, error) {
+	key, err := base32NoPadding.DecodeString(strings.ToUpper(secret))
+	if err != nil {
+		return "", err
+	}
+
+	message := make([]byte, 8)
+	binary.BigEndian.PutUint64(message, uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(message)
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	return fmt.Sprintf("%0*d", totpDigits, value%totpModulus), nil
+}
@@ -0,0 +1,74 @@
+package security
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+//segredo "12345678901234567890" do RFC 6238, em base32
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestTOTPCode(t *testing.T) {
+	//vetores de teste do RFC 6238 para SHA1, com os seis últimos dígitos
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, test := range tests {
+		got, err := totpCode(rfcSecret, test.unix/totpPeriod)
+		if err != nil {
+			t.Fatalf("totpCode(%d) retornou erro: %v", test.unix, err)
+		}
+		if got != test.want {
+			t.Errorf("totpCode(%d) = %s, esperado %s", test.unix, got, test.want)
+		}
+	}
+}
+
+func TestValidateTOTP(t *testing.T) {
+	at := time.Unix(1111111111, 0)
+	step := at.Unix() / totpPeriod
+
+	codeAt := func(step int64) string {
+		code, err := totpCode(rfcSecret, step)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return code
+	}
+
+	tests := []struct {
+		name     string
+		secret   string
+		code     string
+		wantStep int64
+		wantOK   bool
+	}{
+		{"código atual", rfcSecret, codeAt(step), step, true},
+		{"segredo em minúsculas", strings.ToLower(rfcSecret), codeAt(step), step, true},
+		{"código com espaços", rfcSecret, " " + codeAt(step) + "\n", step, true},
+		{"um passo atrás", rfcSecret, codeAt(step - 1), step - 1, true},
+		{"um passo à frente", rfcSecret, codeAt(step + 1), step + 1, true},
+		{"dois passos atrás", rfcSecret, codeAt(step - 2), 0, false},
+		{"código errado", rfcSecret, "000000", 0, false},
+		{"segredo inválido", "não é base32", codeAt(step), 0, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			gotStep, gotOK := ValidateTOTP(test.secret, test.code, at)
+			if gotStep != test.wantStep || gotOK != test.wantOK {
+				t.Errorf("ValidateTOTP() = (%d, %v), esperado (%d, %v)", gotStep, gotOK, test.wantStep, test.wantOK)
+			}
+		})
+	}
+}
@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+//Local guarda os arquivos numa pasta do servidor; serve para desenvolvimento e para quem roda uma réplica só
+type Local struct {
+	Dir string
+}
+
+func (l Local) Put(key string, body io.Reader, size int64, contentType string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+
+	//grava num arquivo temporário e renomeia, para que ninguém leia um arquivo pela metade
+	file, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(file.Name())
+
+	if _, err = io.Copy(file, body); err != nil {
+		file.Close()
+		return err
+	}
+
+	if err = file.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(file.Name(), path)
+}
+
+func (l Local) Get(key string) (io.ReadCloser, error) {
+	path, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) {
+		return nil, ErrNotFound
+	}
+
+	return file, err
+}
+
+func (l Local) Delete(key string) error {
+	path, err := l.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+
+	return nil
+}
+
+func (l Local) path(key string) (string, error) {
+	if !ValidKey(key) {
+		return "", fmt.Errorf("chave de arquivo inválida %s", key)
+	}
+
+	return filepath.Join(l.Dir, filepath.FromSlash(key)), nil
+}
@@ -0,0 +1,170 @@
+package storage
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+//emptyPayload é o SHA-256 de um corpo vazio, usado para assinar GET e DELETE
+const emptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+var s3Client = &http.Client{Timeout: time.Minute}
+
+//S3 guarda os arquivos num bucket de qualquer serviço compatível com S3 (AWS, MinIO, R2...).
+//As requisições usam o endereço no estilo de caminho, Endpoint/Bucket/chave, e são assinadas com AWS Signature V4.
+type S3 struct {
+	Endpoint  string
+	Region    string
+	Bucket    string
+	AccessKey string
+	SecretKey string
+}
+
+//Put envia o corpo sem calcular o hash dele (UNSIGNED-PAYLOAD), para não precisar ler o arquivo duas vezes
+func (s S3) Put(key string, body io.Reader, size int64, contentType string) error {
+	request, err := s.request(http.MethodPut, key, body, "UNSIGNED-PAYLOAD")
+	if err != nil {
+		return err
+	}
+	request.ContentLength = size
+	request.Header.Set("Content-Type", contentType)
+
+	response, err := s3Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	return s3Error(response)
+}
+
+func (s S3) Get(key string) (io.ReadCloser, error) {
+	request, err := s.request(http.MethodGet, key, nil, emptyPayload)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := s3Client.Do(request)
+	if err != nil {
+		return nil, err
+	}
+
+	if response.StatusCode == http.StatusNotFound {
+		response.Body.Close()
+		return nil, ErrNotFound
+	}
+
+	if err = s3Error(response); err != nil {
+		response.Body.Close()
+		return nil, err
+	}
+
+	return response.Body, nil
+}
+
+func (s S3) Delete(key string) error {
+	request, err := s.request(http.MethodDelete, key, nil, emptyPayload)
+	if err != nil {
+		return err
+	}
+
+	response, err := s3Client.Do(request)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode == http.StatusNotFound {
+		return nil
+	}
+
+	return s3Error(response)
+}
+
+func (s S3) request(method, key string, body io.Reader, payloadHash string) (*http.Request, error) {
+	if !ValidKey(key) {
+		return nil, fmt.Errorf("chave de arquivo inválida %s", key)
+	}
+
+	path := "/" + escapePath(s.Bucket) + "/" + escapePath(key)
+	target, err := url.Parse(s.Endpoint + path)
+	if err != nil {
+		return nil, err
+	}
+
+	request, err := http.NewRequest(method, target.String(), body)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	amzDate := now.Format("20060102T150405Z")
+	request.Header.Set("x-amz-date", amzDate)
+	request.Header.Set("x-amz-content-sha256", payloadHash)
+
+	signedHeaders := "host;x-amz-content-sha256;x-amz-date"
+	canonicalRequest := strings.Join([]string{
+		method,
+		path,
+		"",
+		"host:" + target.Host,
+		"x-amz-content-sha256:" + payloadHash,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	day := now.Format("20060102")
+	scope := day + "/" + s.Region + "/s3/aws4_request"
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hashHex(canonicalRequest)
+
+	signingKey := hmacSHA256([]byte("AWS4"+s.SecretKey), day)
+	for _, part := range []string{s.Region, "s3", "aws4_request"} {
+		signingKey = hmacSHA256(signingKey, part)
+	}
+
+	request.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKey, scope, signedHeaders, hex.EncodeToString(hmacSHA256(signingKey, stringToSign)),
+	))
+
+	return request, nil
+}
+
+func s3Error(response *http.Response) error {
+	if response.StatusCode >= 200 && response.StatusCode < 300 {
+		return nil
+	}
+
+	message, _ := io.ReadAll(io.LimitReader(response.Body, 1024))
+	return fmt.Errorf("o armazenamento S3 respondeu %d: %s", response.StatusCode, strings.TrimSpace(string(message)))
+}
+
+//escapePath codifica cada segmento do caminho como a assinatura V4 espera, mantendo as barras
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = strings.ReplaceAll(url.PathEscape(segment), "+", "%2B")
+	}
+
+	return strings.Join(segments, "/")
+}
+
+func hashHex(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, value string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(value))
+	return mac.Sum(nil)
+}
@@ -0,0 +1,73 @@
+package storage
+
+import (
+	"api/src/config"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+//Storage guarda os arquivos enviados pelos usuários. As chaves são caminhos relativos separados por "/".
+type Storage interface {
+	Put(key string, body io.Reader, size int64, contentType string) error
+	//Get retorna ErrNotFound quando a chave não existe
+	Get(key string) (io.ReadCloser, error)
+	//Delete não reclama de uma chave que já não existe
+	Delete(key string) error
+}
+
+var ErrNotFound = errors.New("arquivo não encontrado")
+
+//Default é onde os anexos são guardados; Configure escolhe a implementação conforme MEDIA_STORAGE
+var Default Storage = Local{Dir: "media"}
+
+func Configure() error {
+	switch config.MediaStorage {
+	case "s3":
+		if config.S3Endpoint == "" || config.S3Bucket == "" {
+			return errors.New("MEDIA_STORAGE=s3 precisa de S3_ENDPOINT e S3_BUCKET")
+		}
+
+		Default = S3{
+			Endpoint:  config.S3Endpoint,
+			Region:    config.S3Region,
+			Bucket:    config.S3Bucket,
+			AccessKey: config.S3AccessKey,
+			SecretKey: config.S3SecretKey,
+		}
+	case "", "local":
+		Default = Local{Dir: config.MediaDir}
+	default:
+		return fmt.Errorf("armazenamento de arquivos desconhecido %s", config.MediaStorage)
+	}
+
+	return nil
+}
+
+//ValidKey aceita só chaves com letras minúsculas, números, "-", "_", "." e "/", sem segmentos vazios nem "..",
+//para que nenhuma implementação saia da sua pasta ou do seu bucket
+func ValidKey(key string) bool {
+	if key == "" {
+		return false
+	}
+
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return false
+		}
+	}
+
+	for _, r := range key {
+		if !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || strings.ContainsRune("-_./", r)) {
+			return false
+		}
+	}
+
+	return true
+}
+
+//URL é o endereço público do arquivo, a partir de MEDIA_URL
+func URL(key string) string {
+	return config.MediaURL + "/" + key
+}
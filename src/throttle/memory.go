@@ -0,0 +1,89 @@
+package throttle
+
+import (
+	"sync"
+	"time"
+)
+
+//maxMemoryEntries é a partir de quantas chaves as entradas antigas começam a ser descartadas
+const maxMemoryEntries = 10000
+
+type attempts struct {
+	failures      int
+	lastFailureAt time.Time
+	blockedUntil  time.Time
+}
+
+//MemoryTracker guarda as falhas na memória do processo; cada réplica da API tem a sua contagem
+type MemoryTracker struct {
+	policy  Policy
+	mu      sync.Mutex
+	entries map[string]*attempts
+}
+
+func NewMemoryTracker(policy Policy) *MemoryTracker {
+	return &MemoryTracker{policy: policy, entries: map[string]*attempts{}}
+}
+
+func (m *MemoryTracker) Reserve(key string) (time.Duration, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	if len(m.entries) >= maxMemoryEntries {
+		m.prune(now)
+	}
+
+	entry, ok := m.entries[key]
+	if ok {
+		if wait := entry.blockedUntil.Sub(now); wait > 0 {
+			return wait, nil
+		}
+	}
+
+	if !ok || now.Sub(entry.lastFailureAt) > m.policy.Window {
+		entry = &attempts{}
+		m.entries[key] = entry
+	}
+
+	entry.failures++
+	entry.lastFailureAt = now
+	entry.blockedUntil = now.Add(m.policy.delay(entry.failures))
+
+	return 0, nil
+}
+
+func (m *MemoryTracker) Release(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.entries[key]
+	if !ok {
+		return nil
+	}
+
+	entry.failures--
+	if entry.failures <= 0 {
+		delete(m.entries, key)
+		return nil
+	}
+
+	entry.blockedUntil = entry.lastFailureAt.Add(m.policy.delay(entry.failures))
+	return nil
+}
+
+func (m *MemoryTracker) Reset(key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.entries, key)
+	return nil
+}
+
+func (m *MemoryTracker) prune(now time.Time) {
+	for key, entry := range m.entries {
+		if now.After(entry.blockedUntil) && now.Sub(entry.lastFailureAt) > m.policy.Window {
+			delete(m.entries, key)
+		}
+	}
+}
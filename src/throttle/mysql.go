@@ -0,0 +1,62 @@
+package throttle
+
+import (
+	"api/src/db"
+	"api/src/repositories"
+	"time"
+)
+
+//MySQLTracker guarda as falhas na tabela login_attempts, compartilhada por todas as réplicas da API
+type MySQLTracker struct {
+	scope  string
+	policy Policy
+}
+
+//NewMySQLTracker cria um tracker cujas chaves ficam separadas das de outros trackers pelo scope
+func NewMySQLTracker(scope string, policy Policy) *MySQLTracker {
+	return &MySQLTracker{scope: scope, policy: policy}
+}
+
+func (m *MySQLTracker) Reserve(key string) (time.Duration, error) {
+	db, err := db.ConnectDB()
+	if err != nil {
+		return 0, err
+	}
+	defer db.Close()
+
+	now := time.Now()
+	blockedUntil, err := repositories.NewLoginAttemptRep(db).Reserve(m.key(key), now, now.Add(-m.policy.Window), m.policy.delay)
+	if err != nil {
+		return 0, err
+	}
+
+	if wait := blockedUntil.Sub(now); wait > 0 {
+		return wait, nil
+	}
+
+	return 0, nil
+}
+
+func (m *MySQLTracker) Release(key string) error {
+	db, err := db.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return repositories.NewLoginAttemptRep(db).Release(m.key(key), m.policy.delay)
+}
+
+func (m *MySQLTracker) Reset(key string) error {
+	db, err := db.ConnectDB()
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	return repositories.NewLoginAttemptRep(db).Reset(m.key(key))
+}
+
+func (m *MySQLTracker) key(key string) string {
+	return m.scope + ":" + key
+}
@@ -0,0 +1,109 @@
+package throttle
+
+import (
+	"api/src/config"
+	"time"
+)
+
+//Tracker conta as tentativas de login que falharam por chave (email, IP...) e diz por quanto tempo
+//a chave deve esperar antes de tentar de novo. Cada tentativa é reservada antes de conferir a senha,
+//na mesma operação que checa o bloqueio, para que requisições simultâneas não escapem da contagem
+type Tracker interface {
+	//Reserve retorna quanto falta para a chave poder tentar de novo; se ela está liberada a tentativa
+	//já é contada como falha e o retorno é zero
+	Reserve(key string) (time.Duration, error)
+	//Release devolve uma tentativa reservada que não falhou
+	Release(key string) error
+	//Reset apaga as falhas depois de um login bem sucedido
+	Reset(key string) error
+}
+
+//Policy define a espera depois de cada falha: as primeiras são livres, depois a espera dobra a cada falha
+//até MaxDelay, e ao chegar em LockoutAfter falhas a chave fica bloqueada por LockoutDuration
+type Policy struct {
+	FreeAttempts    int
+	BaseDelay       time.Duration
+	MaxDelay        time.Duration
+	LockoutAfter    int
+	LockoutDuration time.Duration
+	//Window é depois de quanto tempo sem falhas a contagem recomeça
+	Window time.Duration
+}
+
+func (p Policy) delay(failures int) time.Duration {
+	if failures >= p.LockoutAfter {
+		return p.LockoutDuration
+	}
+
+	if failures <= p.FreeAttempts {
+		return 0
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts + 1; i < failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+
+	if delay > p.MaxDelay {
+		return p.MaxDelay
+	}
+
+	return delay
+}
+
+var (
+	//Accounts limita as tentativas por conta (email) e Addresses por IP, que aceita mais falhas por causa de NAT
+	Accounts  Tracker = NewMemoryTracker(accountPolicy(10, 15*time.Minute))
+	Addresses Tracker = NewMemoryTracker(addressPolicy(10, 15*time.Minute))
+	//Emails limita os emails pedidos para um mesmo endereço, como o de redefinição de senha
+	Emails Tracker = NewMemoryTracker(emailPolicy())
+)
+
+//Configure escolhe a implementação dos trackers conforme LOGIN_TRACKER: "memory" (padrão) ou "mysql",
+//que compartilha as contagens entre as réplicas da API
+func Configure() {
+	accounts := accountPolicy(config.LoginLockoutThreshold, config.LoginLockoutDuration)
+	addresses := addressPolicy(config.LoginLockoutThreshold, config.LoginLockoutDuration)
+
+	switch config.LoginTracker {
+	case "mysql":
+		Accounts = NewMySQLTracker("account", accounts)
+		Addresses = NewMySQLTracker("address", addresses)
+		Emails = NewMySQLTracker("email", emailPolicy())
+	default:
+		Accounts = NewMemoryTracker(accounts)
+		Addresses = NewMemoryTracker(addresses)
+		Emails = NewMemoryTracker(emailPolicy())
+	}
+}
+
+func accountPolicy(lockoutAfter int, lockoutDuration time.Duration) Policy {
+	return Policy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Second,
+		MaxDelay:        5 * time.Minute,
+		LockoutAfter:    lockoutAfter,
+		LockoutDuration: lockoutDuration,
+		Window:          24 * time.Hour,
+	}
+}
+
+func addressPolicy(lockoutAfter int, lockoutDuration time.Duration) Policy {
+	policy := accountPolicy(lockoutAfter*5, lockoutDuration)
+	policy.FreeAttempts = 10
+	policy.Window = time.Hour
+	return policy
+}
+
+//emailPolicy deixa pedir alguns emails seguidos e depois espaça os próximos, para que ninguém use a API para
+//encher a caixa de entrada de outra pessoa
+func emailPolicy() Policy {
+	return Policy{
+		FreeAttempts:    3,
+		BaseDelay:       time.Minute,
+		MaxDelay:        time.Hour,
+		LockoutAfter:    10,
+		LockoutDuration: 24 * time.Hour,
+		Window:          24 * time.Hour,
+	}
+}
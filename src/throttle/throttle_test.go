@@ -0,0 +1,107 @@
+package throttle
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPolicyDelay(t *testing.T) {
+	accounts := accountPolicy(10, 15*time.Minute)
+	addresses := addressPolicy(10, 15*time.Minute)
+	capped := Policy{FreeAttempts: 0, BaseDelay: time.Minute, MaxDelay: 5 * time.Minute, LockoutAfter: 100, LockoutDuration: time.Hour}
+
+	tests := []struct {
+		name     string
+		policy   Policy
+		failures int
+		want     time.Duration
+	}{
+		{"conta sem falhas", accounts, 0, 0},
+		{"conta na última falha livre", accounts, 3, 0},
+		{"conta na primeira espera", accounts, 4, time.Second},
+		{"conta dobrando a espera", accounts, 5, 2 * time.Second},
+		{"conta antes do bloqueio", accounts, 9, 32 * time.Second},
+		{"conta bloqueada", accounts, 10, 15 * time.Minute},
+		{"conta depois do bloqueio", accounts, 12, 15 * time.Minute},
+		{"IP na última falha livre", addresses, 10, 0},
+		{"IP na primeira espera", addresses, 11, time.Second},
+		{"IP bloqueado", addresses, 50, 15 * time.Minute},
+		{"espera limitada por MaxDelay", capped, 10, 5 * time.Minute},
+		{"espera logo abaixo de MaxDelay", capped, 3, 4 * time.Minute},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.policy.delay(test.failures); got != test.want {
+				t.Errorf("delay(%d) = %v, esperado %v", test.failures, got, test.want)
+			}
+		})
+	}
+}
+
+func TestMemoryTrackerReserve(t *testing.T) {
+	policy := Policy{FreeAttempts: 2, BaseDelay: time.Hour, MaxDelay: time.Hour, LockoutAfter: 10, LockoutDuration: time.Hour, Window: time.Hour}
+
+	tests := []struct {
+		name    string
+		reserve int
+		release int
+		blocked bool
+	}{
+		{"tentativas livres", 2, 0, false},
+		{"a terceira tentativa bloqueia a próxima", 3, 0, true},
+		{"tentativa devolvida não conta", 3, 1, false},
+		{"devolver mais do que reservou", 1, 3, false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			tracker := NewMemoryTracker(policy)
+
+			for i := 0; i < test.reserve; i++ {
+				if wait, err := tracker.Reserve("ana@exemplo.com"); err != nil || wait != 0 {
+					t.Fatalf("a tentativa %d foi recusada: espera %v, erro %v", i+1, wait, err)
+				}
+			}
+			for i := 0; i < test.release; i++ {
+				if err := tracker.Release("ana@exemplo.com"); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			wait, err := tracker.Reserve("ana@exemplo.com")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if blocked := wait > 0; blocked != test.blocked {
+				t.Errorf("próxima tentativa bloqueada = %v (espera %v), esperado %v", blocked, wait, test.blocked)
+			}
+
+			//a chave bloqueada não pode contar uma tentativa a mais
+			if test.blocked && tracker.entries["ana@exemplo.com"].failures != test.reserve {
+				t.Errorf("falhas = %d, esperado %d", tracker.entries["ana@exemplo.com"].failures, test.reserve)
+			}
+		})
+	}
+}
+
+func TestMemoryTrackerReset(t *testing.T) {
+	tracker := NewMemoryTracker(Policy{BaseDelay: time.Hour, MaxDelay: time.Hour, LockoutAfter: 10, Window: time.Hour})
+
+	if _, err := tracker.Reserve("ana@exemplo.com"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := tracker.Reserve("bia@exemplo.com"); err != nil {
+		t.Fatal(err)
+	}
+	if err := tracker.Reset("ana@exemplo.com"); err != nil {
+		t.Fatal(err)
+	}
+
+	if wait, _ := tracker.Reserve("ana@exemplo.com"); wait != 0 {
+		t.Errorf("a chave zerada continua bloqueada por %v", wait)
+	}
+	if wait, _ := tracker.Reserve("bia@exemplo.com"); wait == 0 {
+		t.Error("zerar uma chave liberou outra")
+	}
+}